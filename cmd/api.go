@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Maximum size of bodies of API requests
+const maxAPIRequestSize = 1 << 20
+
+// Codes of errors returned by the API, along with HTTP status codes
+const (
+	apiInvalidRequest = "invalid_request"
+	apiInvalidPuzzle  = "invalid_puzzle"
+	apiUnknownPuzzle  = "unknown_puzzle"
+	apiNoSolution     = "no_solution"
+	apiLimitReached   = "limit_reached"
+	apiTimeout        = "timeout"
+	apiUnavailable    = "unavailable"
+)
+
+// Request of the solve endpoint: a puzzle in the JSON format (or the text grid format as a JSON string),
+// or a name of a puzzle of the catalog, and options of the search.
+type apiSolveRequest struct {
+	Puzzle json.RawMessage `json:"puzzle"`
+	Name   string          `json:"name"`
+	// Metric of moves, only "slide-1-2" is supported.
+	Metric    string `json:"metric"`
+	Algorithm string `json:"algorithm"`
+	// Limits of the search, which cannot exceed limits of the server.
+	MaxNodes  int `json:"max_nodes"`
+	TimeoutMs int `json:"timeout_ms"`
+}
+
+// Error returned by the API.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"error"`
+}
+
+// Returns a handler of the solve endpoint, solving a puzzle given in the request and returning its report,
+// or an error with a matching status code.
+func apiSolve(solver *klotski.Solver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request apiSolveRequest
+
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIRequestSize))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&request); err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiInvalidRequest, fmt.Sprintf("Invalid request: %s", err))
+			return
+		}
+
+		puzzle, status, code, err := getAPIPuzzle(request)
+		if err != nil {
+			writeAPIError(w, status, code, err.Error())
+			return
+		}
+
+		opts, err := getAPISolveOptions(request)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, apiInvalidRequest, err.Error())
+			return
+		}
+
+		ctx, cancel := withTimeout(r.Context())
+		defer cancel()
+
+		if request.TimeoutMs > 0 {
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(request.TimeoutMs)*time.Millisecond)
+			defer cancel()
+		}
+
+		board := puzzle.Board()
+
+		solution, err := solver.SolveWithOptions(ctx, &board, board.State, opts)
+
+		// Nobody reads the response of a client which went away.
+		if err != nil && r.Context().Err() != nil {
+			log.Printf("Client of %s went away: %s", r.URL.Path, err)
+			return
+		}
+
+		if err != nil {
+			status, code := getAPIErrorStatus(err)
+			writeAPIError(w, status, code, err.Error())
+			return
+		}
+
+		// Solutions are not recorded in the store, so anonymous clients cannot replace records of named puzzles.
+		writeAPIResponse(w, http.StatusOK, klotski.NewReport(puzzle, solution))
+	}
+}
+
+// Returns the puzzle of a request. Returns the status and the code of the error if there is none or it is invalid,
+// including boards larger than the limits of the parser, so their search is never started.
+func getAPIPuzzle(request apiSolveRequest) (klotski.Puzzle, int, string, error) {
+	hasPuzzle := len(request.Puzzle) > 0 && string(request.Puzzle) != "null"
+
+	switch {
+	case hasPuzzle && request.Name != "":
+		return klotski.Puzzle{}, http.StatusBadRequest, apiInvalidRequest, fmt.Errorf("Either a puzzle or a name is expected, not both")
+	case request.Name != "":
+		puzzle, err := klotski.FindPuzzle(request.Name)
+		if err != nil {
+			return puzzle, http.StatusNotFound, apiUnknownPuzzle, err
+		}
+
+		return puzzle, 0, "", nil
+	case !hasPuzzle:
+		return klotski.Puzzle{}, http.StatusBadRequest, apiInvalidRequest, fmt.Errorf("A puzzle or a name is expected")
+	}
+
+	data := []byte(request.Puzzle)
+
+	// Puzzles in the text grid format are given as JSON strings.
+	var text string
+
+	if err := json.Unmarshal(data, &text); err == nil {
+		data = []byte(text)
+	}
+
+	puzzle, err := klotski.DecodePuzzle(data)
+	if err != nil {
+		return puzzle, http.StatusBadRequest, apiInvalidPuzzle, err
+	}
+
+	return puzzle, 0, "", nil
+}
+
+// Returns options of a search of a request, within limits given by flags of the server. The external algorithm
+// cannot be requested, since anonymous clients should not fill the disk of the server with temporary files.
+func getAPISolveOptions(request apiSolveRequest) (klotski.SolveOptions, error) {
+	opts := getSolveOptions()
+
+	if request.Metric != "" && request.Metric != klotski.MoveMetric {
+		return opts, fmt.Errorf("Unsupported metric %q, want: %s", request.Metric, klotski.MoveMetric)
+	}
+
+	switch klotski.Algorithm(request.Algorithm) {
+	case "":
+	case klotski.AlgorithmBFS, klotski.AlgorithmFrontier:
+		opts.Algorithm = klotski.Algorithm(request.Algorithm)
+	default:
+		return opts, fmt.Errorf("Unsupported algorithm %q, want: %s or %s", request.Algorithm, klotski.AlgorithmBFS, klotski.AlgorithmFrontier)
+	}
+
+	if request.MaxNodes < 0 || request.TimeoutMs < 0 {
+		return opts, fmt.Errorf("Limits cannot be negative")
+	}
+
+	if request.MaxNodes > 0 && (opts.MaxNodes == 0 || request.MaxNodes < opts.MaxNodes) {
+		opts.MaxNodes = request.MaxNodes
+	}
+
+	return opts, nil
+}
+
+// Returns the status and the code of an error of a search.
+func getAPIErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, klotski.ErrNoSolution):
+		return http.StatusUnprocessableEntity, apiNoSolution
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, apiTimeout
+	case errors.Is(err, klotski.ErrNodeLimit), errors.Is(err, klotski.ErrMemoryLimit):
+		return http.StatusUnprocessableEntity, apiLimitReached
+	}
+
+	return http.StatusServiceUnavailable, apiUnavailable
+}
+
+// Writes an error of the API.
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIResponse(w, status, apiError{Code: code, Message: message})
+}
+
+// Writes a response of the API in the JSON format.
+func writeAPIResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+func TestAPISolve(t *testing.T) {
+	tooLarge := `{"goal": {"label": "a", "x": 0, "y": 0}, "grid": ["a` + strings.Repeat(".", klotski.MaxBoardWidth) + `"]}`
+
+	tests := []struct {
+		name string
+		body string
+		// Limit of the server given with the -max-nodes flag.
+		maxNodes int
+		// Whether the client is gone before the request is handled, so no response is written.
+		cancelled bool
+		status    int
+		code      string
+		moves     int
+	}{
+		{name: "catalog puzzle", body: `{"name": "heng-dao-li-ma"}`, status: http.StatusOK, moves: 90},
+		{name: "puzzle in the JSON format", body: `{"puzzle": {"goal": {"label": "a", "x": 0, "y": 1}, "grid": ["aa.", "aa.", "..."]}, "algorithm": "frontier", "metric": "slide-1-2"}`, status: http.StatusOK, moves: 1},
+		{name: "puzzle in the text grid format", body: `{"puzzle": "goal: a 1 0\n\na a .\n"}`, status: http.StatusOK, moves: 1},
+		{name: "malformed JSON", body: `{"name": `, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "unknown field", body: `{"name": "heng-dao-li-ma", "depth": 10}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "puzzle and name", body: `{"name": "heng-dao-li-ma", "puzzle": "goal: a 1 0\n\na a .\n"}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "no puzzle", body: `{}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "unknown metric", body: `{"name": "heng-dao-li-ma", "metric": "slide-1"}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "unknown algorithm", body: `{"name": "heng-dao-li-ma", "algorithm": "dfs"}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "external algorithm", body: `{"name": "heng-dao-li-ma", "algorithm": "external"}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "negative limit", body: `{"name": "heng-dao-li-ma", "max_nodes": -1}`, status: http.StatusBadRequest, code: apiInvalidRequest},
+		{name: "invalid puzzle", body: `{"puzzle": "goal: a 0 0\n\na a\na .\n"}`, status: http.StatusBadRequest, code: apiInvalidPuzzle},
+		{name: "too large board", body: `{"puzzle": ` + tooLarge + `}`, status: http.StatusBadRequest, code: apiInvalidPuzzle},
+		{name: "unknown puzzle", body: `{"name": "unknown"}`, status: http.StatusNotFound, code: apiUnknownPuzzle},
+		{name: "no solution", body: `{"puzzle": "goal: a 1 0\n\na b\nc d\n"}`, status: http.StatusUnprocessableEntity, code: apiNoSolution},
+		{name: "node limit of the request", body: `{"name": "heng-dao-li-ma", "max_nodes": 10}`, maxNodes: 1000000, status: http.StatusUnprocessableEntity, code: apiLimitReached},
+		{name: "node limit clamped to the server", body: `{"name": "heng-dao-li-ma", "max_nodes": 1000000}`, maxNodes: 10, status: http.StatusUnprocessableEntity, code: apiLimitReached},
+		{name: "timeout", body: `{"name": "heng-dao-li-ma", "timeout_ms": 1}`, status: http.StatusGatewayTimeout, code: apiTimeout},
+		{name: "client gone", body: `{"name": "heng-dao-li-ma"}`, cancelled: true},
+	}
+
+	defer func(limit int, store *klotski.Store) { maxNodes, puzzleStore = limit, store }(maxNodes, puzzleStore)
+
+	store, err := klotski.OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Cannot open store, got: %v", err)
+	}
+
+	puzzleStore = store
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			maxNodes = test.maxNodes
+
+			solver := klotski.NewSolver(1, klotski.SolveOptions{})
+			defer solver.Close()
+
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/solve", strings.NewReader(test.body))
+
+			if test.cancelled {
+				ctx, cancel := context.WithCancel(request.Context())
+				cancel()
+				request = request.WithContext(ctx)
+			}
+
+			recorder := httptest.NewRecorder()
+			apiSolve(solver)(recorder, request)
+
+			if test.cancelled {
+				if recorder.Body.Len() > 0 || len(recorder.Header()) > 0 {
+					t.Errorf("Response written to a client which went away, got: %d, %s", recorder.Code, recorder.Body)
+				}
+
+				return
+			}
+
+			if recorder.Code != test.status {
+				t.Fatalf("Incorrect status, got: %d, want: %d, body: %s", recorder.Code, test.status, recorder.Body)
+			}
+
+			if test.status != http.StatusOK {
+				var response apiError
+
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Code != test.code || response.Message == "" {
+					t.Errorf("Incorrect error, got: %s, want code: %s", recorder.Body, test.code)
+				}
+
+				return
+			}
+
+			var report klotski.Report
+
+			if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil || len(report.Moves) != test.moves {
+				t.Errorf("Incorrect report, got: %d moves, %v, want: %d moves", len(report.Moves), err, test.moves)
+			}
+		})
+	}
+
+	// Anonymous clients cannot replace records of the store.
+	if records, err := store.List(); err != nil || len(records) != 0 {
+		t.Errorf("Solutions of the API recorded in the store, got: %d, %v", len(records), err)
+	}
+}
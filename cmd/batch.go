@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Statuses of puzzles solved by the batch command
+const (
+	batchOK         = "ok"
+	batchMismatch   = "mismatch"
+	batchTimeout    = "timeout"
+	batchLimit      = "limit"
+	batchNoSolution = "no solution"
+	batchError      = "error"
+)
+
+// Puzzle solved by the batch command, with the result of solving it.
+type batchEntry struct {
+	// Name of the puzzle or, for puzzles without a name, its file and position in the file.
+	name   string
+	puzzle klotski.Puzzle
+	// Error of reading the puzzle, if it cannot be solved at all.
+	err error
+
+	moves    int
+	expanded int
+	elapsed  time.Duration
+	status   string
+}
+
+// Solves puzzles of files and directories in parallel and prints a summary of them. Returns an error
+// if any of the puzzles has not been solved or has been solved in a number of moves other than expected.
+func runBatch(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	flags.IntVar(&workers, "workers", runtime.NumCPU(), "maximum number of puzzles solved at the same time")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	entries, err := loadBatch(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	start := time.Now()
+	opts := getSolveOptions()
+	slots := make(chan struct{}, workers)
+
+	var wait sync.WaitGroup
+
+	for idx := range entries {
+		if entries[idx].err != nil {
+			entries[idx].status = batchError
+			continue
+		}
+
+		wait.Add(1)
+
+		go func(entry *batchEntry) {
+			defer wait.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			solveBatchEntry(entry, opts)
+		}(&entries[idx])
+	}
+
+	wait.Wait()
+
+	failed := printBatchSummary(os.Stdout, entries, time.Since(start))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d puzzles failed or have not been solved in the expected number of moves", failed, len(entries))
+	}
+
+	return nil
+}
+
+// Solves a puzzle of the batch within the timeout given by flags, counted from the start of its search.
+func solveBatchEntry(entry *batchEntry, opts klotski.SolveOptions) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	board := entry.puzzle.Board()
+	solution, err := board.SolveContext(ctx, opts)
+
+	entry.moves, entry.expanded, entry.elapsed = len(solution.Moves), solution.Stats.NodesExpanded, solution.Stats.Elapsed
+
+	var limitErr *klotski.LimitError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		entry.status = batchTimeout
+	case errors.As(err, &limitErr):
+		entry.status = batchLimit
+	case errors.Is(err, klotski.ErrNoSolution):
+		entry.status = batchNoSolution
+	case err != nil:
+		entry.status, entry.err = batchError, err
+	case entry.puzzle.Moves > 0 && entry.puzzle.Moves != entry.moves:
+		entry.status = batchMismatch
+	default:
+		entry.status = batchOK
+	}
+
+	if entry.status != batchOK && entry.status != batchMismatch {
+		entry.moves = 0
+	}
+
+	if limitErr != nil {
+		entry.expanded, entry.elapsed = limitErr.Stats.NodesExpanded, limitErr.Stats.Elapsed
+	}
+}
+
+// Prints a table of solved puzzles and totals. Returns the number of puzzles which failed or have been solved
+// in a number of moves other than expected.
+func printBatchSummary(w io.Writer, entries []batchEntry, elapsed time.Duration) int {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	counts := make(map[string]int)
+
+	fmt.Fprintln(writer, "PUZZLE\tMOVES\tEXPECTED\tEXPANDED\tTIME\tSTATUS")
+
+	for _, entry := range entries {
+		counts[entry.status]++
+
+		moves, expected := "-", "-"
+
+		if entry.status == batchOK || entry.status == batchMismatch {
+			moves = fmt.Sprint(entry.moves)
+		}
+
+		if entry.puzzle.Moves > 0 {
+			expected = fmt.Sprint(entry.puzzle.Moves)
+		}
+
+		status := entry.status
+
+		if entry.err != nil {
+			status = fmt.Sprintf("%s: %s", entry.status, entry.err)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\t%s\n", entry.name, moves, expected, entry.expanded, entry.elapsed.Round(time.Millisecond), status)
+	}
+
+	writer.Flush()
+
+	failed := len(entries) - counts[batchOK]
+
+	fmt.Fprintf(w, "\n%d puzzles in %s: %d solved, %d mismatched, %d timed out, %d limited, %d without solution, %d errors\n",
+		len(entries), elapsed.Round(time.Millisecond), counts[batchOK], counts[batchMismatch], counts[batchTimeout],
+		counts[batchLimit], counts[batchNoSolution], counts[batchError])
+
+	return failed
+}
+
+// Loads puzzles of the batch from files and directories (all .txt and .json files in them, recursively),
+// or the whole catalog if there are none. Unreadable files become entries with errors, so they are reported
+// along with other puzzles.
+func loadBatch(paths []string) ([]batchEntry, error) {
+	if len(paths) == 0 {
+		puzzles, err := klotski.Catalog()
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]batchEntry, 0, len(puzzles))
+
+		for _, puzzle := range puzzles {
+			entries = append(entries, batchEntry{name: puzzle.Name, puzzle: puzzle})
+		}
+
+		return entries, nil
+	}
+
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read puzzles: %s", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if ext := filepath.Ext(file); !entry.IsDir() && (ext == ".txt" || ext == ".json") {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read puzzles: %s", err)
+		}
+	}
+
+	var entries []batchEntry
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+
+		if err == nil {
+			var puzzles []klotski.Puzzle
+
+			if puzzles, err = klotski.DecodePuzzles(content); err == nil {
+				for idx, puzzle := range puzzles {
+					name := puzzle.Name
+
+					if name == "" {
+						name = fmt.Sprintf("%s#%d", file, idx+1)
+					}
+
+					entries = append(entries, batchEntry{name: name, puzzle: puzzle})
+				}
+
+				continue
+			}
+		}
+
+		entries = append(entries, batchEntry{name: file, err: err})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("Cannot find puzzles in %s", strings.Join(paths, ", "))
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	puzzle := "goal: a 1 0\n\na a .\n"
+	classic := "goal: b 1 3\n\na b b c\na b b c\nd e e f\nd g h f\ni . . j\n"
+
+	type row struct {
+		moves  string
+		status string
+	}
+
+	tests := []struct {
+		name string
+		// Puzzle files of the batch by their names.
+		files map[string]string
+		args  []string
+		// Rows of the summary by files of their puzzles.
+		rows    map[string]row
+		counts  string
+		wantErr bool
+	}{
+		{
+			name:   "expected moves",
+			files:  map[string]string{"ok.txt": "moves: 1\n" + puzzle},
+			rows:   map[string]row{"ok.txt#1": {"1", batchOK}},
+			counts: "1 solved, 0 mismatched, 0 timed out, 0 limited, 0 without solution, 0 errors",
+		},
+		{
+			name:   "unknown moves",
+			files:  map[string]string{"ok.txt": puzzle},
+			rows:   map[string]row{"ok.txt#1": {"1", batchOK}},
+			counts: "1 solved, 0 mismatched, 0 timed out, 0 limited, 0 without solution, 0 errors",
+		},
+		{
+			name:   "solved puzzle",
+			files:  map[string]string{"solved.txt": "goal: a 0 0\n\na a .\n"},
+			rows:   map[string]row{"solved.txt#1": {"0", batchOK}},
+			counts: "1 solved, 0 mismatched, 0 timed out, 0 limited, 0 without solution, 0 errors",
+		},
+		{
+			name:    "mismatch",
+			files:   map[string]string{"ok.txt": puzzle, "mismatch.txt": "moves: 5\n" + puzzle},
+			rows:    map[string]row{"ok.txt#1": {"1", batchOK}, "mismatch.txt#1": {"1", batchMismatch}},
+			counts:  "1 solved, 1 mismatched, 0 timed out, 0 limited, 0 without solution, 0 errors",
+			wantErr: true,
+		},
+		{
+			name:    "invalid file",
+			files:   map[string]string{"ok.txt": puzzle, "invalid.txt": "goal: a 0 0\n\na a\na .\n"},
+			rows:    map[string]row{"ok.txt#1": {"1", batchOK}, "invalid.txt": {"-", batchError}},
+			counts:  "1 solved, 0 mismatched, 0 timed out, 0 limited, 0 without solution, 1 errors",
+			wantErr: true,
+		},
+		{
+			name:    "no solution",
+			files:   map[string]string{"none.txt": "goal: a 1 0\n\na b\nc d\n"},
+			rows:    map[string]row{"none.txt#1": {"-", batchNoSolution}},
+			counts:  "0 solved, 0 mismatched, 0 timed out, 0 limited, 1 without solution, 0 errors",
+			wantErr: true,
+		},
+		{
+			name:    "node limit",
+			files:   map[string]string{"classic.txt": classic},
+			args:    []string{"-max-nodes", "10"},
+			rows:    map[string]row{"classic.txt#1": {"-", batchLimit}},
+			counts:  "0 solved, 0 mismatched, 0 timed out, 1 limited, 0 without solution, 0 errors",
+			wantErr: true,
+		},
+		{
+			name:    "timeout",
+			files:   map[string]string{"classic.txt": classic},
+			args:    []string{"-timeout", "1ns"},
+			rows:    map[string]row{"classic.txt#1": {"-", batchTimeout}},
+			counts:  "0 solved, 0 mismatched, 1 timed out, 0 limited, 0 without solution, 0 errors",
+			wantErr: true,
+		},
+		{name: "no puzzles", files: map[string]string{"notes.md": puzzle}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Cannot write puzzle, got: %v", err)
+				}
+			}
+
+			output := captureStdout(t, func() {
+				err := runBatch(flag.NewFlagSet("batch", flag.ContinueOnError), append(test.args, dir))
+
+				if (err != nil) != test.wantErr {
+					t.Errorf("Incorrect error, got: %v, want error: %v", err, test.wantErr)
+				}
+			})
+
+			// Batches without puzzles are not summarized.
+			if test.rows == nil {
+				if output != "" {
+					t.Errorf("Incorrect output, got: %q, want none", output)
+				}
+
+				return
+			}
+
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			rows := make(map[string]row)
+
+			if len(lines) < 3 {
+				t.Fatalf("Incorrect summary, got: %q", output)
+			}
+
+			// Rows of puzzles follow the header and precede a blank line and the totals.
+			for _, line := range lines[1 : len(lines)-2] {
+				fields := strings.Fields(line)
+
+				if len(fields) < 6 {
+					t.Fatalf("Incorrect row of the summary, got: %q", line)
+				}
+
+				status := strings.Join(fields[5:], " ")
+
+				if idx := strings.Index(status, ":"); idx >= 0 {
+					status = status[:idx]
+				}
+
+				rows[strings.TrimPrefix(fields[0], dir+string(filepath.Separator))] = row{moves: fields[1], status: status}
+			}
+
+			if len(rows) != len(test.rows) {
+				t.Errorf("Incorrect number of rows, got: %d, want: %d, output: %s", len(rows), len(test.rows), output)
+			}
+
+			for name, want := range test.rows {
+				if got := rows[name]; got != want {
+					t.Errorf("Incorrect row of %s, got: %+v, want: %+v", name, got, want)
+				}
+			}
+
+			if !strings.HasSuffix(strings.TrimSpace(output), test.counts) {
+				t.Errorf("Incorrect totals, got: %s, want: %s", lines[len(lines)-1], test.counts)
+			}
+		})
+	}
+}
+
+// Returns everything written to the standard output by a function.
+func captureStdout(t *testing.T, fn func()) string {
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatalf("Cannot create file for the standard output, got: %v", err)
+	}
+
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatalf("Cannot read the standard output, got: %v", err)
+	}
+
+	return string(content)
+}
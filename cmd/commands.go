@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Solves a puzzle and prints each state of its solution.
+func runSolve(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	addCacheFlags(flags)
+	addStoreFlag(flags)
+	flags.BoolVar(&progress, "progress", false, "report progress of solving the puzzle")
+	flags.StringVar(&checkpoint, "checkpoint", "", "file to periodically save the search to")
+	flags.BoolVar(&resume, "resume", false, "resume the search saved in the checkpoint file")
+	addOutputFlag(flags)
+	replay := flags.Bool("replay", false, "replay the solution in the terminal, redrawing the board after each move")
+	delay := flags.Duration("delay", 500*time.Millisecond, "delay between moves of the replay")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if err := validateOutput(); err != nil {
+		return err
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	if output == outputText && !resume && printStoredSolution(puzzle) {
+		return nil
+	}
+
+	board := puzzle.Board()
+	search, err := board.NewSearch(board.State)
+
+	if resume {
+		search, err = klotski.LoadCheckpoint(checkpoint)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	board = *search.Board
+	initialState := search.States[0]
+
+	// A resumed search may be of any puzzle and start from any state, which is then the reported puzzle.
+	if resume {
+		puzzle = search.Puzzle()
+	}
+
+	opts := klotski.SolveOptions{Checkpoint: checkpoint}
+
+	ndjson := newNDJSONWriter(os.Stdout)
+
+	if output == outputNDJSON {
+		if err := ndjson.start(puzzle); err != nil {
+			return err
+		}
+	}
+
+	if progress {
+		opts.Progress = printProgress
+
+		if output == outputNDJSON {
+			opts.Progress = ndjson.progress
+		}
+	}
+
+	solution, err := solve(context.Background(), search, opts)
+	if err != nil {
+		if output == outputNDJSON {
+			ndjson.fail(err, solution.Stats)
+		}
+
+		return err
+	}
+
+	// Checkpoints do not hold names of puzzles, which identify them in the store.
+	if !resume {
+		recordSolution(puzzle, solution)
+	}
+
+	switch output {
+	case outputJSON:
+		return writeJSON(os.Stdout, klotski.NewReport(puzzle, solution))
+	case outputCSV:
+		return writeCSV(os.Stdout, klotski.NewReport(puzzle, solution))
+	case outputNDJSON:
+		return ndjson.finish(klotski.NewReport(puzzle, solution))
+	}
+
+	notation := klotski.Notation(solution.Moves)
+	states := make([]klotski.State, len(solution.Moves))
+
+	for step, move := range solution.Moves {
+		states[step] = move.After
+	}
+
+	if *replay && isTerminal(os.Stdout) {
+		if err := replaySolution(puzzle, &board, initialState, solution.Moves, *delay); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nSolution: %s\n", klotski.FormatMoves(notation))
+	} else {
+		printSolution(&board, initialState, notation, states)
+	}
+
+	if solution.Cached {
+		fmt.Println("Solution taken from the cache")
+	} else {
+		fmt.Printf("Expanded %d states (%d visited) in %s\n", solution.Stats.NodesExpanded, solution.Stats.Visited, solution.Stats.Elapsed)
+	}
+
+	return nil
+}
+
+// Checks that moves given in compact notation solve a puzzle. Returns an error if they do not.
+func runVerify(flags *flag.FlagSet, args []string) error {
+	notation := flags.String("moves", "", "moves in compact notation, i.e. \"jL iRR\"")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	pieceMoves, err := klotski.ParseMoves(*notation)
+	if err != nil {
+		return err
+	}
+
+	board := puzzle.Board()
+
+	states, err := board.ApplyMoves(board.State, pieceMoves)
+	if err != nil {
+		return err
+	}
+
+	if len(states) == 0 || !board.IsSolved(states[len(states)-1]) {
+		return fmt.Errorf("Moves do not solve the puzzle")
+	}
+
+	fmt.Printf("Solved in %d moves", len(pieceMoves))
+
+	if puzzle.Moves > 0 {
+		fmt.Printf(", optimal solution has %d moves", puzzle.Moves)
+	}
+
+	fmt.Println()
+
+	return nil
+}
+
+// Generates a new puzzle by making random moves from the initial state of a puzzle and prints it
+// in the text grid format.
+func runGenerate(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	steps := flags.Int("steps", 100, "number of random moves made from the initial state")
+	seed := flags.Int64("seed", 0, "seed of random moves, 0 means a random one")
+	name := flags.String("name", "", "name of the generated puzzle")
+	solveGenerated := flags.Bool("solve", false, "solve the generated puzzle to find its optimal number of moves")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	random := rand.New(rand.NewSource(*seed))
+	board := puzzle.Board()
+	state := board.State
+
+	// A solved state is not a puzzle, so the walk goes on until it leaves one.
+	for step := 0; step < *steps || board.IsSolved(state); step++ {
+		moves := board.Moves(state)
+		if len(moves) == 0 {
+			break
+		}
+
+		state = moves[random.Intn(len(moves))].After
+	}
+
+	generated := klotski.Puzzle{
+		Name:   *name,
+		Tags:   []string{"generated"},
+		Width:  board.Width,
+		Height: board.Height,
+		Goal:   board.Goal,
+		Pieces: state.Pieces,
+	}
+
+	if *solveGenerated {
+		ctx, cancel := withTimeout(context.Background())
+		defer cancel()
+
+		solution, err := board.SolveFrom(ctx, state, getSolveOptions())
+		if err != nil {
+			return err
+		}
+
+		generated.Moves = len(solution.Moves)
+	}
+
+	fmt.Printf("# Generated from %s with seed %d\n", puzzle.Name, *seed)
+	fmt.Print(generated.String())
+
+	return nil
+}
+
+// Prints properties of a puzzle and statistics of solving it.
+func runAnalyze(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	board := puzzle.Board()
+	sizes := make(map[string]int)
+	blocks := 0
+
+	for _, piece := range board.State.Pieces {
+		sizes[fmt.Sprintf("%dx%d", piece.Width, piece.Height)]++
+		blocks += len(piece.Blocks)
+	}
+
+	sizeNames := make([]string, 0, len(sizes))
+
+	for size, count := range sizes {
+		sizeNames = append(sizeNames, fmt.Sprintf("%s: %d", size, count))
+	}
+
+	sort.Strings(sizeNames)
+
+	if puzzle.Name != "" {
+		fmt.Printf("Puzzle:          %s\n", puzzle.Name)
+	}
+
+	fmt.Printf("Board:           %dx%d, goal %s at %d,%d\n", board.Width, board.Height, board.Goal.Label, board.Goal.X, board.Goal.Y)
+	fmt.Printf("Pieces:          %d (%s)\n", len(board.State.Pieces), strings.Join(sizeNames, ", "))
+	fmt.Printf("Empty spaces:    %d\n", board.Width*board.Height-blocks)
+	fmt.Printf("Possible moves:  %d\n", len(board.Moves(board.State)))
+
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	// Statistics are only known for solutions actually searched for, so the cache is not used.
+	solution, err := board.SolveContext(ctx, getSolveOptions())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Optimal moves:   %d", len(solution.Moves))
+
+	if puzzle.Moves > 0 && puzzle.Moves != len(solution.Moves) {
+		fmt.Printf(" (%d expected)", puzzle.Moves)
+	}
+
+	fmt.Println()
+	fmt.Printf("Expanded states: %d\n", solution.Stats.NodesExpanded)
+	fmt.Printf("Visited states:  %d\n", solution.Stats.Visited)
+	fmt.Printf("Elapsed:         %s\n", solution.Stats.Elapsed)
+
+	return nil
+}
+
+// Renders the initial state of a puzzle, or its states after given moves, as text, an SVG or PNG image
+// or an animated GIF.
+func runRender(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	notation := flags.String("moves", "", "moves in compact notation to apply first, i.e. \"jL iRR\"")
+	solveMoves := flags.Bool("solve", false, "render each state of the optimal solution instead of given moves")
+	steps := flags.Bool("steps", false, "render the state after each of the moves, not only the last one")
+	format := flags.String("format", outputText, "format of the image: text, svg, png or gif (an animation of the moves)")
+	file := flags.String("file", "", "file to write the image to instead of the standard output")
+	columns := flags.Int("columns", klotski.DefaultColumns, "number of states in a row of an SVG or PNG image of steps")
+	frames := flags.Int("frames", klotski.DefaultFramesPerMove, "number of frames of each move of a GIF animation")
+	delay := flags.Duration("delay", 300*time.Millisecond, "duration of each move of a GIF animation")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	switch *format {
+	case outputText, outputSVG:
+	case outputPNG, outputGIF:
+		if *file == "" && isTerminal(os.Stdout) {
+			return fmt.Errorf("Cannot write a %s image to a terminal, give a file with -file", *format)
+		}
+	default:
+		return fmt.Errorf("Unknown image format %q, want: text, svg, png or gif", *format)
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	board := puzzle.Board()
+
+	pieceMoves, err := klotski.ParseMoves(*notation)
+	if err != nil {
+		return err
+	}
+
+	if *solveMoves {
+		ctx, cancel := withTimeout(context.Background())
+		defer cancel()
+
+		solution, err := board.SolveContext(ctx, getSolveOptions())
+		if err != nil {
+			return err
+		}
+
+		pieceMoves, *steps = klotski.Notation(solution.Moves), true
+	}
+
+	states, err := board.ApplyMoves(board.State, pieceMoves)
+	if err != nil {
+		return err
+	}
+
+	state, highlight := board.State, ""
+
+	if len(states) > 0 {
+		state, highlight = states[len(states)-1], pieceMoves[len(pieceMoves)-1].Label
+	}
+
+	var image bytes.Buffer
+
+	switch {
+	case *format == outputSVG && *steps:
+		image.WriteString(klotski.SVGRenderer{}.RenderSolution(&board, board.State, pieceMoves, states, *columns))
+	case *format == outputSVG:
+		image.WriteString(klotski.SVGRenderer{}.Render(&board, state, highlight))
+	case *format == outputPNG && *steps:
+		err = png.Encode(&image, klotski.RasterRenderer{}.DrawSolution(&board, board.State, pieceMoves, states, *columns))
+	case *format == outputPNG:
+		err = png.Encode(&image, klotski.RasterRenderer{}.Draw(&board, state, highlight))
+	case *format == outputGIF:
+		err = gif.EncodeAll(&image, klotski.RasterRenderer{}.Animate(&board, board.State, pieceMoves, states, *frames, *delay))
+	default:
+		// Files get plain ASCII, like pipes.
+		var renderer klotski.Renderer = klotski.TextRenderer{}
+
+		if *file == "" {
+			renderer = getRenderer()
+		}
+
+		if !*steps {
+			image.WriteString(renderer.Render(&board, state, highlight) + "\n")
+			break
+		}
+
+		image.WriteString(renderer.Render(&board, board.State, "") + "\n")
+
+		for step, state := range states {
+			image.WriteString(fmt.Sprintf("%d) %s\n\n", step+1, pieceMoves[step]))
+			image.WriteString(renderer.Render(&board, state, pieceMoves[step].Label) + "\n")
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("Cannot encode image: %s", err)
+	}
+
+	if *file == "" {
+		_, err := os.Stdout.Write(image.Bytes())
+		return err
+	}
+
+	if err := os.WriteFile(*file, image.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Cannot write image: %s", err)
+	}
+
+	return nil
+}
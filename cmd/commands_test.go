@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSolveNDJSON(t *testing.T) {
+	classic := "goal: b 1 3\n\na b b c\na b b c\nd e e f\nd g h f\ni . . j\n"
+
+	tests := []struct {
+		name   string
+		puzzle string
+		args   []string
+		// Type of the last event.
+		last     string
+		expanded int
+		wantErr  bool
+	}{
+		{name: "solved", puzzle: "goal: a 1 0\n\na a .\n", last: "done"},
+		{name: "node limit", puzzle: classic, args: []string{"-max-nodes", "10"}, last: "error", expanded: 10, wantErr: true},
+		{name: "no solution", puzzle: "goal: a 1 0\n\na b\nc d\n", last: "error", expanded: 1, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "puzzle.txt")
+
+			if err := os.WriteFile(path, []byte(test.puzzle), 0644); err != nil {
+				t.Fatalf("Cannot write puzzle, got: %v", err)
+			}
+
+			args := append([]string{"-output", "ndjson", "-cache-size", "0"}, test.args...)
+
+			output := captureStdout(t, func() {
+				err := runSolve(flag.NewFlagSet("solve", flag.ContinueOnError), append(args, path))
+
+				if (err != nil) != test.wantErr {
+					t.Errorf("Incorrect error, got: %v, want error: %v", err, test.wantErr)
+				}
+			})
+
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+
+			var first, last ndjsonEvent
+
+			if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Type != "start" {
+				t.Errorf("Incorrect first event, got: %s, %v, want type: start", lines[0], err)
+			}
+
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil || last.Type != test.last {
+				t.Fatalf("Incorrect last event, got: %s, %v, want type: %s", lines[len(lines)-1], err, test.last)
+			}
+
+			if test.last == "error" && (last.Error == "" || last.Stats == nil || last.Stats.NodesExpanded != test.expanded) {
+				t.Errorf("Incorrect error event, got: %s, want error and %d expanded states", lines[len(lines)-1], test.expanded)
+			}
+		})
+	}
+}
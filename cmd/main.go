@@ -1,231 +1,250 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"flag"
 	"fmt"
-	"html/template"
-	"log"
-	"net/http"
+	"io"
+	"os"
 	"strings"
+	"time"
 
-	"github.com/gorilla/mux"
 	klotski "github.com/mfiedorowicz/klotski-go/pkg"
 )
 
+// Name of the catalog puzzle used when no puzzle is given
+const defaultPuzzle = "Heng Dao Li Ma"
+
+// Name of the executable in usage messages
+const programName = "klotski-go"
+
+// Command of the executable, i.e. "solve", with its own flags.
+type command struct {
+	name string
+	// Arguments following flags, i.e. "[puzzle]".
+	args    string
+	summary string
+	// Registers flags of the command, parses them with arguments and runs it.
+	run func(flags *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"solve", "[puzzle]", "Solves a puzzle and prints each state of its solution.", runSolve},
+	{"verify", "-moves MOVES [puzzle]", "Checks that moves in compact notation solve a puzzle.", runVerify},
+	{"generate", "[puzzle]", "Generates a new puzzle by making random moves from the initial state of a puzzle.", runGenerate},
+	{"analyze", "[puzzle]", "Prints properties of a puzzle and statistics of solving it.", runAnalyze},
+	{"play", "[puzzle]", "Plays a puzzle with arrow keys in a terminal, or with moves in compact notation read line by line outside of one or with -lines.", runPlay},
+	{"render", "[puzzle]", "Renders a state of a puzzle, optionally after given moves, as text or an image.", runRender},
+	{"batch", "[file or directory...]", "Solves puzzles of files and directories, or the whole catalog, and prints a summary of them.", runBatch},
+	{"serve", "[puzzle]", "Runs the HTTP server showing the solution of a puzzle.", runServer},
+	{"list", "", "Lists puzzles recorded in the store.", runList},
+}
+
+// Flags shared by commands, registered by each command using them
 var (
-	mode = flag.String("mode", "cli", "run mode (cli default)")
+	puzzlePath string
+	timeout    time.Duration
+	maxNodes   int
+	progress   bool
+	checkpoint string
+	resume     bool
+	algorithm  string
+	tempDir    string
+	workers    int
+	cacheSize  int
+	cacheDir   string
+	storeDir   string
 )
 
-func main() {
+var (
+	// Cache of solutions shared by all solves, nil if the command does not solve puzzles
+	solutionCache *klotski.SolutionCache
 
-	flag.Parse()
+	// Store of solved puzzles, nil if not enabled
+	puzzleStore *klotski.Store
+)
 
-	switch *mode {
-	case "cli":
-		runCli()
-	case "http":
-		runServer()
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "-help" || os.Args[1] == "help" {
+		printUsage()
+		os.Exit(2)
 	}
-}
 
-func runCli() {
-	board := initBoard()
-	initialState := board.State
-	results, err := board.Solve()
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
 
-	if err != nil {
-		fmt.Printf("Error occured: %s", err)
-	} else {
-		fmt.Printf("\nInitial State:\n\n")
-		fmt.Println(board.Print(initialState))
+		flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+		flags.Usage = func() {
+			fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] %s\n\n%s\n\n", programName, cmd.name, cmd.args, cmd.summary)
+
+			if strings.Contains(cmd.args, "puzzle") {
+				fmt.Fprintf(flags.Output(), "A puzzle is a catalog name, i.e. %q, a file in the text grid or JSON format, or \"-\" for the standard input, %q by default.\n\n", "heng-dao-li-ma", defaultPuzzle)
+			}
+
+			fmt.Fprintln(flags.Output(), "Flags:")
+			flags.PrintDefaults()
+		}
 
-		fmt.Printf("\nNumber of moves needed to reach final state: %d\n\n", len(results))
-		for step, state := range results {
-			fmt.Printf("%d) %s moves %s\n\n", step+1, state.MovePiece.Label, state.MoveDirection)
-			fmt.Println(board.Print(state))
+		if strings.Contains(cmd.args, "puzzle") {
+			flags.StringVar(&puzzlePath, "puzzle", "", "file with the puzzle in the text grid or JSON format, \"-\" reads it from the standard input")
 		}
+
+		if err := cmd.run(flags, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error occured: %s\n", err)
+			os.Exit(1)
+		}
+
+		return
 	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", os.Args[1])
+	printUsage()
+	os.Exit(2)
 }
 
-func runServer() {
-	router := mux.NewRouter()
+// Prints commands of the executable.
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", programName)
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
 
-	router.HandleFunc("/", homePage).Methods("GET")
-	router.HandleFunc("/solution", solutionHTMLPage).Methods("GET")
-	log.Println("Listening on port 8000. Open http://localhost:8000")
-	log.Fatal(http.ListenAndServe(":8000", router))
+	fmt.Fprintf(os.Stderr, "\nRun \"%s <command> -h\" for flags of a command.\n", programName)
 }
 
-func homePage(w http.ResponseWriter, r *http.Request) {
-	board := initBoard()
-	initialState := board.State
+// Registers flags limiting searches and choosing their algorithm.
+func addLimitFlags(flags *flag.FlagSet) {
+	flags.DurationVar(&timeout, "timeout", 30*time.Second, "maximum time of solving a puzzle, 0 means no limit")
+	flags.IntVar(&maxNodes, "max-nodes", 0, "maximum number of states expanded while solving a puzzle, 0 means no limit")
+	flags.StringVar(&algorithm, "algorithm", "bfs", "search algorithm: bfs (in memory), frontier (last two layers in memory) or external (on disk)")
+	flags.StringVar(&tempDir, "temp-dir", "", "directory for temporary files of the external search")
+}
 
-	results, err := board.Solve()
+// Registers flags of the solution cache.
+func addCacheFlags(flags *flag.FlagSet) {
+	flags.IntVar(&cacheSize, "cache-size", 100, "maximum number of solutions cached in memory")
+	flags.StringVar(&cacheDir, "cache-dir", "", "directory to cache solutions in, so they survive restarts")
+}
 
-	var resultsHTML template.HTML
+// Registers the flag of the store of solved puzzles.
+func addStoreFlag(flags *flag.FlagSet) {
+	flags.StringVar(&storeDir, "store", "", "directory to record solved puzzles in, so they can be browsed later")
+}
 
-	if err == nil {
-		var buffer bytes.Buffer
+// Parses flags of a command and opens the solution cache and the store, if their flags are registered.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
 
-		buffer.WriteString(fmt.Sprintf("<p>Number of moves needed to reach final state: <strong>%d</strong></p>", len(results)))
+	var err error
 
-		for step, state := range results {
-			buffer.WriteString("<div class=\"state\">")
-			buffer.WriteString(fmt.Sprintf("<p>%d) <strong>%s</strong> moves <strong>%s</strong></p>", step+1, state.MovePiece.Label, state.MoveDirection))
-			buffer.WriteString(strings.Replace(board.Print(state), "\n", "<br>", -1))
-			buffer.WriteString("</div>")
+	if flags.Lookup("cache-size") != nil {
+		if solutionCache, err = klotski.NewSolutionCache(cacheSize, cacheDir); err != nil {
+			return err
 		}
+	}
 
-		resultsHTML = template.HTML(buffer.String())
+	if storeDir != "" {
+		if puzzleStore, err = klotski.OpenStore(storeDir); err != nil {
+			return err
+		}
 	}
 
-	title := "Klotski Go"
+	return nil
+}
 
-	data := struct {
-		Title        string
-		InitialState template.HTML
-		Solution     template.HTML
-	}{
-		title,
-		template.HTML(strings.Replace(board.Print(initialState), "\n", "<br>", -1)),
-		resultsHTML,
+// Loads the puzzle given with the -puzzle flag or as the only argument of a command: a catalog name,
+// a file in the text grid or JSON format, or "-" for the standard input. Returns the default puzzle if there is none.
+func loadPuzzle(flags *flag.FlagSet) (klotski.Puzzle, error) {
+	if flags.NArg() > 1 || flags.NArg() == 1 && puzzlePath != "" {
+		return klotski.Puzzle{}, fmt.Errorf("Too many arguments, want: one puzzle")
 	}
 
-	tpl := template.Must(template.ParseFiles("cmd/templates/layout.html"))
-	tpl.Execute(w, data)
+	if puzzlePath != "" {
+		return readPuzzle(puzzlePath)
+	}
+
+	if flags.NArg() == 0 {
+		return klotski.FindPuzzle(defaultPuzzle)
+	}
+
+	if _, err := os.Stat(flags.Arg(0)); os.IsNotExist(err) && flags.Arg(0) != "-" {
+		return klotski.FindPuzzle(flags.Arg(0))
+	}
+
+	return readPuzzle(flags.Arg(0))
 }
 
-func solutionHTMLPage(w http.ResponseWriter, r *http.Request) {
-	board := initBoard()
-	results, err := board.Solve()
+// Reads a puzzle in the text grid or JSON format from a file or, for "-", from the standard input.
+func readPuzzle(path string) (klotski.Puzzle, error) {
+	var content []byte
+	var err error
 
-	if err == nil {
-		var buffer bytes.Buffer
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
 
-		buffer.WriteString(fmt.Sprintf("<p>Number of moves: %d</p>", len(results)))
+	if err != nil {
+		return klotski.Puzzle{}, fmt.Errorf("Cannot read puzzle: %s", err)
+	}
 
-		resultsHTML := template.HTML(buffer.String())
+	return klotski.DecodePuzzle(content)
+}
 
-		title := "Klotski Go"
+// Prints the initial state, each state of a solution and the solution in compact notation.
+func printSolution(board *klotski.Board, initialState klotski.State, notation []klotski.PieceMove, states []klotski.State) {
+	renderer := getRenderer()
 
-		data := struct {
-			Title   string
-			Results template.HTML
-		}{
-			title,
-			resultsHTML,
-		}
+	fmt.Printf("\nInitial State:\n\n")
+	fmt.Println(renderer.Render(board, initialState, ""))
 
-		tpl := template.Must(template.ParseFiles("cmd/templates/solution.html"))
-		tpl.Execute(w, data)
+	fmt.Printf("\nNumber of moves needed to reach final state: %d\n\n", len(states))
+	for step, state := range states {
+		fmt.Printf("%d) %s\n\n", step+1, notation[step])
+		fmt.Println(renderer.Render(board, state, notation[step].Label))
 	}
+
+	fmt.Printf("Solution: %s\n", klotski.FormatMoves(notation))
+}
+
+// Prints progress of solving a puzzle in a single line of the standard error.
+func printProgress(stats klotski.Stats) {
+	fmt.Fprintf(os.Stderr, "\rdepth %d, expanded %d, frontier %d, visited %d, %s   ",
+		stats.Depth, stats.NodesExpanded, stats.FrontierSize, stats.Visited, stats.Elapsed.Round(time.Millisecond))
 }
 
-// Initialises a board
-func initBoard() klotski.Board {
-	board := klotski.Board{
-		Width:  4,
-		Height: 5,
-		State: klotski.State{
-			Pieces: []klotski.Piece{
-				klotski.Piece{
-					Label:  "a",
-					Width:  1,
-					Height: 2,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 0, Y: 0},
-						klotski.Block{X: 0, Y: 1},
-					},
-				},
-				klotski.Piece{
-					Label:  "b",
-					Width:  2,
-					Height: 2,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 1, Y: 0},
-						klotski.Block{X: 2, Y: 0},
-						klotski.Block{X: 1, Y: 1},
-						klotski.Block{X: 2, Y: 1},
-					},
-				},
-				klotski.Piece{
-					Label:  "c",
-					Width:  1,
-					Height: 2,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 3, Y: 0},
-						klotski.Block{X: 3, Y: 1},
-					},
-				},
-				klotski.Piece{
-					Label:  "d",
-					Width:  1,
-					Height: 2,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 0, Y: 2},
-						klotski.Block{X: 0, Y: 3},
-					},
-				},
-				klotski.Piece{
-					Label:  "e",
-					Width:  2,
-					Height: 1,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 1, Y: 2},
-						klotski.Block{X: 2, Y: 2},
-					},
-				},
-				klotski.Piece{
-					Label:  "f",
-					Width:  1,
-					Height: 2,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 3, Y: 2},
-						klotski.Block{X: 3, Y: 3},
-					},
-				},
-				klotski.Piece{
-					Label:  "g",
-					Width:  1,
-					Height: 1,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 1, Y: 3},
-					},
-				},
-				klotski.Piece{
-					Label:  "h",
-					Width:  1,
-					Height: 1,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 2, Y: 3},
-					},
-				},
-				klotski.Piece{
-					Label:  "i",
-					Width:  1,
-					Height: 1,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 0, Y: 4},
-					},
-				},
-				klotski.Piece{
-					Label:  "j",
-					Width:  1,
-					Height: 1,
-					Blocks: []klotski.Block{
-						klotski.Block{X: 3, Y: 4},
-					},
-				},
-			},
-		},
+// Runs a search within limits given by flags.
+func solve(ctx context.Context, search *klotski.Search, opts klotski.SolveOptions) (klotski.Solution, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	defaults := getSolveOptions()
+	opts.MaxNodes, opts.Algorithm, opts.TempDir, opts.Cache = defaults.MaxNodes, defaults.Algorithm, defaults.TempDir, defaults.Cache
+
+	return search.SolveContext(ctx, opts)
+}
+
+// Returns options of a search given by flags.
+func getSolveOptions() klotski.SolveOptions {
+	return klotski.SolveOptions{
+		MaxNodes:  maxNodes,
+		Algorithm: klotski.Algorithm(algorithm),
+		TempDir:   tempDir,
+		Cache:     solutionCache,
 	}
+}
 
-	board.ZobristHash = board.InitZorbistHash()
-	board.State.Hash = board.GetZobristHash(board.State)
-	board.State.Parent = nil
-	board.States = append(board.States, board.State)
-	board.VisitedStatesHashes = make(map[int]bool, 0)
+// Returns a context limited by the timeout given by flags.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
 
-	return board
+	return context.WithCancel(ctx)
 }
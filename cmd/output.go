@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Formats of solutions printed by commands
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputCSV    = "csv"
+	outputNDJSON = "ndjson"
+	outputSVG    = "svg"
+	outputPNG    = "png"
+	outputGIF    = "gif"
+)
+
+// Format of solutions given by the -output flag
+var output string
+
+// Columns of solutions in the CSV format
+var csvHeader = []string{"step", "piece", "direction", "distance", "notation", "from_x", "from_y", "to_x", "to_y", "board"}
+
+// Line of the NDJSON format, one per event: "start" with the puzzle, "progress" with stats of the search so far,
+// "move" for each move of the solution and "done" with stats of the whole search.
+type ndjsonEvent struct {
+	Type    string               `json:"type"`
+	Version int                  `json:"version,omitempty"`
+	Puzzle  *klotski.Puzzle      `json:"puzzle,omitempty"`
+	Metric  string               `json:"metric,omitempty"`
+	Move    *klotski.ReportMove  `json:"move,omitempty"`
+	Stats   *klotski.ReportStats `json:"stats,omitempty"`
+	Cached  bool                 `json:"cached,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// Registers the flag of the output format.
+func addOutputFlag(flags *flag.FlagSet) {
+	flags.StringVar(&output, "output", outputText, "format of the solution: text, json, csv or ndjson (one JSON object per line, streamed while solving)")
+}
+
+// Checks the output format given by flags.
+func validateOutput() error {
+	switch output {
+	case outputText, outputJSON, outputCSV, outputNDJSON:
+		return nil
+	}
+
+	return fmt.Errorf("Unknown output format %q, want: text, json, csv or ndjson", output)
+}
+
+// Writes a report of a solution in the JSON format.
+func writeJSON(w io.Writer, report klotski.Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// Writes moves of a report in the CSV format, one row per move, with rows of the board after the move
+// separated by "/".
+func writeCSV(w io.Writer, report klotski.Report) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, move := range report.Moves {
+		record := []string{
+			strconv.Itoa(move.Step),
+			move.Piece,
+			move.Direction,
+			strconv.Itoa(move.Distance),
+			move.Notation,
+			strconv.Itoa(move.From.X),
+			strconv.Itoa(move.From.Y),
+			strconv.Itoa(move.To.X),
+			strconv.Itoa(move.To.Y),
+			strings.Join(move.Board, "/"),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Writes events of the NDJSON format.
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+// Returns a writer of NDJSON events.
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{encoder: json.NewEncoder(w)}
+}
+
+// Writes the event starting a solve of a puzzle.
+func (writer *ndjsonWriter) start(puzzle klotski.Puzzle) error {
+	return writer.encoder.Encode(ndjsonEvent{Type: "start", Version: klotski.ReportVersion, Puzzle: &puzzle, Metric: klotski.MoveMetric})
+}
+
+// Writes progress of a search.
+func (writer *ndjsonWriter) progress(stats klotski.Stats) {
+	reportStats := klotski.NewReportStats(stats)
+
+	writer.encoder.Encode(ndjsonEvent{Type: "progress", Stats: &reportStats})
+}
+
+// Writes moves of a report, followed by the event ending the solve.
+func (writer *ndjsonWriter) finish(report klotski.Report) error {
+	for idx := range report.Moves {
+		if err := writer.encoder.Encode(ndjsonEvent{Type: "move", Move: &report.Moves[idx]}); err != nil {
+			return err
+		}
+	}
+
+	return writer.encoder.Encode(ndjsonEvent{Type: "done", Stats: &report.Stats, Cached: report.Cached})
+}
+
+// Writes the event ending a solve which failed, with statistics of the search up to the failure.
+func (writer *ndjsonWriter) fail(err error, stats klotski.Stats) error {
+	reportStats := klotski.NewReportStats(stats)
+
+	return writer.encoder.Encode(ndjsonEvent{Type: "error", Stats: &reportStats, Error: err.Error()})
+}
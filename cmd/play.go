@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Plays a puzzle: interactively in a terminal or, if the standard input or output is not one,
+// with moves in compact notation read line by line.
+func runPlay(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	lines := flags.Bool("lines", false, "read moves in compact notation line by line, even in a terminal")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if puzzlePath == "-" || flags.Arg(0) == "-" {
+		return fmt.Errorf("Cannot play a puzzle read from the standard input, moves are read from it")
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	if *lines || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return playLines(puzzle)
+	}
+
+	return playTerminal(puzzle)
+}
+
+// Plays a puzzle in a terminal in raw mode: arrows slide the selected piece, Tab selects the next one.
+func playTerminal(puzzle klotski.Puzzle) error {
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	defer restore()
+
+	fmt.Print(ansiAltScreen + ansiHideCursor)
+	defer fmt.Print(ansiShowCursor + ansiMainScreen)
+
+	board := puzzle.Board()
+	game := klotski.NewGame(&board)
+	selected := getPieceIndex(game.State(), board.Goal.Label)
+	message := ""
+
+	directions := map[key]klotski.Direction{
+		keyUp:    {X: 0, Y: -1},
+		keyDown:  {X: 0, Y: 1},
+		keyLeft:  {X: -1, Y: 0},
+		keyRight: {X: 1, Y: 0},
+	}
+
+	for {
+		state := game.State()
+		solved := game.IsSolved()
+
+		if solved {
+			drawSolved(os.Stdout, puzzle, game)
+		} else {
+			drawGame(os.Stdout, puzzle, game, selected, message)
+		}
+
+		press, err := readKey(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		message = ""
+
+		if direction, ok := directions[press.key]; ok && !solved {
+			if err := game.Slide(state.Pieces[selected].Label, direction); errors.Is(err, klotski.ErrIllegalMove) {
+				message = fmt.Sprintf("Piece %s cannot move %s", state.Pieces[selected].Label, direction)
+			}
+
+			continue
+		}
+
+		switch {
+		case press.key == keyTab:
+			selected = (selected + 1) % len(state.Pieces)
+		case press.key == keyBackTab:
+			selected = (selected + len(state.Pieces) - 1) % len(state.Pieces)
+		case press.key == keyBackspace || press.char == 'u':
+			if !game.Undo() {
+				message = "Nothing to undo"
+			}
+		case press.char == 'r':
+			game.Restart()
+		case (press.char == 'h' || press.char == '?') && !solved:
+			drawGame(os.Stdout, puzzle, game, selected, "Looking for the best move...")
+
+			ctx, cancel := withTimeout(context.Background())
+			hint, err := game.Hint(ctx, getSolveOptions())
+			cancel()
+
+			if err != nil {
+				message = fmt.Sprintf("No hint: %s", err)
+			} else {
+				selected = getPieceIndex(state, hint.Piece.Label)
+				message = fmt.Sprintf("Hint: %s (piece %s %s by %d)", hint, hint.Piece.Label, hint.Direction, hint.Distance)
+			}
+		case press.key == keyEscape || press.key == keyInterrupt || press.char == 'q':
+			return nil
+		}
+	}
+}
+
+// Draws the board of a game with the selected piece highlighted, the move counter and a message.
+func drawGame(w io.Writer, puzzle klotski.Puzzle, game *klotski.Game, selected int, message string) {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(ansiClear)
+	buffer.WriteString(fmt.Sprintf("%s%s%s   Moves: %d\n\n", ansiBold, puzzle.Name, ansiReset, len(game.Moves())))
+	buffer.WriteString(klotski.ANSIRenderer{}.Render(game.Board, game.State(), game.State().Pieces[selected].Label))
+	buffer.WriteString(fmt.Sprintf("\n%s\n\n", message))
+	buffer.WriteString(ansiFaint + "arrows slide the piece, tab selects the next one, u undo, h hint, r restart, q quit" + ansiReset + "\n")
+
+	w.Write(buffer.Bytes())
+}
+
+// Draws the congratulation screen of a solved game.
+func drawSolved(w io.Writer, puzzle klotski.Puzzle, game *klotski.Game) {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(ansiClear)
+	name := puzzle.Name
+
+	if name == "" {
+		name = "the puzzle"
+	}
+
+	buffer.WriteString(fmt.Sprintf("%sCongratulations!%s You solved %s in %d moves.\n\n", ansiBold, ansiReset, name, len(game.Moves())))
+	buffer.WriteString(klotski.ANSIRenderer{}.Render(game.Board, game.State(), ""))
+
+	if puzzle.Moves > 0 {
+		buffer.WriteString(fmt.Sprintf("\nThe shortest solution has %d moves.\n", puzzle.Moves))
+	}
+
+	buffer.WriteString("\n" + ansiFaint + "u undo, r play again, q quit" + ansiReset + "\n")
+
+	w.Write(buffer.Bytes())
+}
+
+// Returns index of a piece with a given label in a state, 0 if there is none.
+func getPieceIndex(state klotski.State, label string) int {
+	for idx, piece := range state.Pieces {
+		if piece.Label == label {
+			return idx
+		}
+	}
+
+	return 0
+}
+
+// Plays a puzzle with moves in compact notation read line by line from the standard input.
+func playLines(puzzle klotski.Puzzle) error {
+	board := puzzle.Board()
+	history := []klotski.State{board.State}
+	scanner := bufio.NewScanner(os.Stdin)
+
+	fmt.Println("Enter moves in compact notation, i.e. \"jL iRR\", \"undo\" or \"quit\".")
+
+	for {
+		state := history[len(history)-1]
+
+		fmt.Printf("\n%s\nMoves: %d\n", board.Print(state), len(history)-1)
+
+		if board.IsSolved(state) {
+			fmt.Printf("Solved in %d moves!\n", len(history)-1)
+			return nil
+		}
+
+		fmt.Print("> ")
+
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		switch line := strings.TrimSpace(scanner.Text()); line {
+		case "":
+		case "quit", "q":
+			return nil
+		case "undo", "u":
+			if len(history) > 1 {
+				history = history[:len(history)-1]
+			}
+		default:
+			pieceMoves, err := klotski.ParseMoves(line)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+
+			states, err := board.ApplyMoves(state, pieceMoves)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+
+			history = append(history, states...)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Limits of the delay between steps of a replay changed with "+" and "-"
+const (
+	minReplayDelay = 25 * time.Millisecond
+	maxReplayDelay = 5 * time.Second
+)
+
+// Replays moves of a solution in a terminal, redrawing the board in place after each of them with a given delay.
+// If the standard input is a terminal, space pauses and resumes, arrows step back and forward, "+" and "-" change
+// the speed and "q" quits. Otherwise the replay runs until the last move.
+func replaySolution(puzzle klotski.Puzzle, board *klotski.Board, initialState klotski.State, moves []klotski.Move, delay time.Duration) error {
+	keys := make(chan keyPress)
+
+	if isTerminal(os.Stdin) {
+		restore, err := makeRaw(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		defer restore()
+
+		go func() {
+			for {
+				press, err := readKey(os.Stdin)
+				if err != nil {
+					close(keys)
+					return
+				}
+
+				keys <- press
+			}
+		}()
+	}
+
+	fmt.Print(ansiClear + ansiHideCursor)
+	defer fmt.Print(ansiShowCursor)
+
+	step, paused := 0, false
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		drawReplay(os.Stdout, puzzle, board, initialState, moves, step, paused, delay)
+
+		if step == len(moves) && !isTerminal(os.Stdin) {
+			return nil
+		}
+
+		select {
+		case <-timer.C:
+			if !paused && step < len(moves) {
+				step++
+			}
+
+			timer.Reset(delay)
+		case press, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			switch {
+			case press.char == ' ' || press.char == 'p':
+				paused = !paused
+
+				// Resuming at the end starts the replay again.
+				if !paused && step == len(moves) {
+					step = 0
+				}
+			case press.key == keyRight || press.char == 'n' || press.char == 'l':
+				paused = true
+
+				if step < len(moves) {
+					step++
+				}
+			case press.key == keyLeft || press.char == 'b' || press.char == 'h':
+				paused = true
+
+				if step > 0 {
+					step--
+				}
+			case press.char == '+' || press.char == '=':
+				delay = maxDuration(delay/2, minReplayDelay)
+			case press.char == '-':
+				delay = minDuration(delay*2, maxReplayDelay)
+			case press.char == '0' || press.key == keyBackspace:
+				step, paused = 0, false
+			case press.key == keyEscape || press.key == keyInterrupt || press.char == 'q':
+				return nil
+			}
+		}
+
+		// A finished replay waits for keys only.
+		if step == len(moves) {
+			paused = true
+		}
+	}
+}
+
+// Draws a step of a replay from the top left corner of the terminal: the board after the move of the step
+// with the moved piece highlighted, the move and the controls.
+func drawReplay(w io.Writer, puzzle klotski.Puzzle, board *klotski.Board, initialState klotski.State, moves []klotski.Move, step int, paused bool, delay time.Duration) {
+	var buffer bytes.Buffer
+
+	state, moved, description := initialState, "", "Initial state"
+
+	if step > 0 {
+		move := moves[step-1]
+		state, moved = move.After, move.Piece.Label
+		description = fmt.Sprintf("Move %d of %d: %s (piece %s %s by %d)", step, len(moves), move, move.Piece.Label, move.Direction, move.Distance)
+	}
+
+	status := fmt.Sprintf("playing, %s per move", delay)
+
+	if step == len(moves) {
+		status = "solved"
+	} else if paused {
+		status = "paused"
+	}
+
+	buffer.WriteString(ansiHome)
+	buffer.WriteString(fmt.Sprintf("%s%s%s   %s%s\n\n", ansiBold, puzzle.Name, ansiReset, status, ansiClearLine))
+	buffer.WriteString(klotski.ANSIRenderer{}.Render(board, state, moved))
+	buffer.WriteString(fmt.Sprintf("\n%s%s\n\n", description, ansiClearLine))
+	buffer.WriteString(ansiFaint + "space pause, arrows step back and forward, + and - change speed, 0 restart, q quit" + ansiReset + ansiClearLine + "\n")
+	buffer.WriteString(ansiClearDown)
+
+	w.Write(buffer.Bytes())
+}
+
+// Returns the greater of two durations.
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// Returns the smaller of two durations.
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
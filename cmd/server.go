@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"runtime"
+
+	"github.com/gorilla/mux"
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Runs the HTTP server showing the solution of a puzzle and, if the store is enabled, recorded puzzles.
+func runServer(flags *flag.FlagSet, args []string) error {
+	addLimitFlags(flags)
+	addCacheFlags(flags)
+	addStoreFlag(flags)
+	flags.IntVar(&workers, "workers", runtime.NumCPU(), "maximum number of puzzles solved at the same time")
+	addr := flags.String("addr", ":8000", "address to listen on")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	puzzle, err := loadPuzzle(flags)
+	if err != nil {
+		return err
+	}
+
+	board := puzzle.Board()
+	solver := klotski.NewSolver(workers, getSolveOptions())
+	router := mux.NewRouter()
+
+	router.HandleFunc("/", homePage(solver, puzzle, &board)).Methods("GET")
+	router.HandleFunc("/solution", solutionHTMLPage(solver, &board)).Methods("GET")
+	router.HandleFunc("/api/v1/solve", apiSolve(solver)).Methods("POST")
+
+	if puzzleStore != nil {
+		router.HandleFunc("/puzzles", puzzlesPage).Methods("GET")
+		router.HandleFunc("/puzzles/{id}", puzzlePage).Methods("GET")
+		router.HandleFunc("/puzzles/{id}", deletePuzzle).Methods("DELETE")
+		router.HandleFunc("/puzzles/{id}/rating", ratePuzzle).Methods("POST")
+	}
+
+	log.Printf("Listening on %s. Open http://localhost%s", *addr, *addr)
+
+	return http.ListenAndServe(*addr, router)
+}
+
+// Returns a handler of the home page, showing the initial state of a board and each state of its solution.
+func homePage(solver *klotski.Solver, puzzle klotski.Puzzle, board *klotski.Board) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		initialState := board.State
+
+		ctx, cancel := withTimeout(r.Context())
+		defer cancel()
+
+		solution, err := solver.Solve(ctx, board, board.State)
+		results := solution.Moves
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		recordSolution(puzzle, solution)
+
+		var buffer bytes.Buffer
+
+		buffer.WriteString(fmt.Sprintf("<p>Number of moves needed to reach final state: <strong>%d</strong></p>", len(results)))
+
+		for step, move := range results {
+			buffer.WriteString("<div class=\"state\">")
+			buffer.WriteString(fmt.Sprintf("<p>%d) <strong>%s</strong> moves <strong>%s</strong></p>", step+1, move.Piece.Label, move.Direction))
+			buffer.WriteString(klotski.SVGRenderer{}.Render(board, move.After, move.Piece.Label))
+			buffer.WriteString("</div>")
+		}
+
+		resultsHTML := template.HTML(buffer.String())
+
+		title := "Klotski Go"
+
+		data := struct {
+			Title        string
+			InitialState template.HTML
+			Solution     template.HTML
+		}{
+			title,
+			template.HTML(klotski.SVGRenderer{}.Render(board, initialState, "")),
+			resultsHTML,
+		}
+
+		tpl := template.Must(template.ParseFiles("cmd/templates/layout.html"))
+		tpl.Execute(w, data)
+	}
+}
+
+// Returns a handler of the page showing the number of moves of a board solution.
+func solutionHTMLPage(solver *klotski.Solver, board *klotski.Board) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := withTimeout(r.Context())
+		defer cancel()
+
+		solution, err := solver.Solve(ctx, board, board.State)
+		results := solution.Moves
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		} else {
+			var buffer bytes.Buffer
+
+			buffer.WriteString(fmt.Sprintf("<p>Number of moves: %d</p>", len(results)))
+
+			resultsHTML := template.HTML(buffer.String())
+
+			title := "Klotski Go"
+
+			data := struct {
+				Title   string
+				Results template.HTML
+			}{
+				title,
+				resultsHTML,
+			}
+
+			tpl := template.Must(template.ParseFiles("cmd/templates/solution.html"))
+			tpl.Execute(w, data)
+		}
+	}
+}
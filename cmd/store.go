@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// Records a solution of a puzzle in the store, if it is enabled. Solutions taken from the cache are not recorded,
+// since they have no statistics of their search.
+func recordSolution(puzzle klotski.Puzzle, solution klotski.Solution) {
+	if puzzleStore == nil || solution.Cached {
+		return
+	}
+
+	if _, err := puzzleStore.SavePuzzleSolution(puzzle, solution); err != nil {
+		log.Printf("Cannot record solution of %s: %s", puzzle.Name, err)
+	}
+}
+
+// Prints a solution of a puzzle recorded in the store, if there is one with the same layout. Returns false otherwise.
+func printStoredSolution(puzzle klotski.Puzzle) bool {
+	if puzzleStore == nil {
+		return false
+	}
+
+	record, err := puzzleStore.GetPuzzle(puzzle)
+	if err != nil || !record.Solved {
+		return false
+	}
+
+	notation, err := klotski.ParseMoves(record.Solution)
+	if err != nil {
+		return false
+	}
+
+	states, err := record.SolutionStates()
+	if err != nil {
+		return false
+	}
+
+	board := record.Puzzle.Board()
+
+	printSolution(&board, board.State, notation, states)
+	fmt.Printf("Solution taken from the store, expanded %d states (%d visited) in %s\n",
+		record.Stats.NodesExpanded, record.Stats.Visited, record.Stats.Elapsed)
+
+	return true
+}
+
+// Prints puzzles recorded in the store.
+func runList(flags *flag.FlagSet, args []string) error {
+	addStoreFlag(flags)
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if puzzleStore == nil {
+		return fmt.Errorf("No store given, see the -store flag")
+	}
+
+	records, err := puzzleStore.List()
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		moves := "not solved"
+		if record.Solved {
+			moves = fmt.Sprintf("%d moves", record.Stats.Depth)
+		}
+
+		fmt.Printf("%-20s %-20s %-12s rating %.1f (%d)\n", record.ID, record.Puzzle.Name, moves, record.Rating(), record.Ratings)
+	}
+
+	return nil
+}
+
+// Shows puzzles recorded in the store.
+func puzzlesPage(w http.ResponseWriter, r *http.Request) {
+	records, err := puzzleStore.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title   string
+		Puzzles []klotski.StoredPuzzle
+	}{
+		"Klotski Go - Puzzles",
+		records,
+	}
+
+	tpl := template.Must(template.ParseFiles("cmd/templates/puzzles.html"))
+	tpl.Execute(w, data)
+}
+
+// Shows a puzzle recorded in the store with its solution, without solving it again.
+func puzzlePage(w http.ResponseWriter, r *http.Request) {
+	record, err := puzzleStore.Get(mux.Vars(r)["id"])
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	board := record.Puzzle.Board()
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("<p>Rating: <strong>%.1f</strong> (%d ratings)</p>", record.Rating(), record.Ratings))
+	buffer.WriteString(fmt.Sprintf("<form method=\"post\" action=\"/puzzles/%s/rating\">", template.HTMLEscapeString(record.ID)))
+
+	for rating := klotski.MinRating; rating <= klotski.MaxRating; rating++ {
+		buffer.WriteString(fmt.Sprintf("<button name=\"rating\" value=\"%d\">%d</button> ", rating, rating))
+	}
+
+	buffer.WriteString("</form>")
+
+	if !record.Solved {
+		buffer.WriteString("<p>Not solved yet.</p>")
+	} else {
+		states, err := record.SolutionStates()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		notation, _ := klotski.ParseMoves(record.Solution)
+
+		buffer.WriteString(fmt.Sprintf("<p>Number of moves needed to reach final state: <strong>%d</strong></p>", len(states)))
+
+		for step, state := range states {
+			buffer.WriteString("<div class=\"state\">")
+			buffer.WriteString(fmt.Sprintf("<p>%d) <strong>%s</strong></p>", step+1, notation[step]))
+			buffer.WriteString(klotski.SVGRenderer{}.Render(&board, state, notation[step].Label))
+			buffer.WriteString("</div>")
+		}
+	}
+
+	data := struct {
+		Title        string
+		InitialState template.HTML
+		Solution     template.HTML
+	}{
+		"Klotski Go - " + record.Puzzle.Name,
+		template.HTML(klotski.SVGRenderer{}.Render(&board, board.State, "")),
+		template.HTML(buffer.String()),
+	}
+
+	tpl := template.Must(template.ParseFiles("cmd/templates/layout.html"))
+	tpl.Execute(w, data)
+}
+
+// Rates a puzzle recorded in the store with the "rating" form value.
+func ratePuzzle(w http.ResponseWriter, r *http.Request) {
+	rating, err := strconv.Atoi(r.FormValue("rating"))
+	if err != nil {
+		http.Error(w, "Invalid rating", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := puzzleStore.Rate(mux.Vars(r)["id"], rating); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/puzzles/"+mux.Vars(r)["id"], http.StatusSeeOther)
+}
+
+// Deletes a puzzle recorded in the store.
+func deletePuzzle(w http.ResponseWriter, r *http.Request) {
+	if err := puzzleStore.Delete(mux.Vars(r)["id"]); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Writes an error returned by the store with a matching status code.
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, klotski.ErrPuzzleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, klotski.ErrInvalidRating):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	klotski "github.com/mfiedorowicz/klotski-go/pkg"
+)
+
+// ANSI escape sequences controlling the terminal
+const (
+	ansiClear      = "\x1b[H\x1b[2J"
+	ansiHome       = "\x1b[H"
+	ansiClearLine  = "\x1b[K"
+	ansiClearDown  = "\x1b[J"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+	ansiReset      = "\x1b[0m"
+	ansiBold       = "\x1b[1m"
+	ansiFaint      = "\x1b[2m"
+)
+
+// Special keys read from the terminal
+type key int
+
+const (
+	keyChar key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyTab
+	keyBackTab
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyInterrupt
+	keyUnknown
+)
+
+// Key pressed in the terminal: a special key or a character.
+type keyPress struct {
+	key  key
+	char rune
+}
+
+// Reads a single key pressed in a terminal in raw mode.
+func readKey(reader io.Reader) (keyPress, error) {
+	var buffer [8]byte
+
+	n, err := reader.Read(buffer[:])
+	if err != nil {
+		return keyPress{}, err
+	}
+
+	input := buffer[:n]
+
+	// Escape sequences of special keys arrive in a single read, i.e. "\x1b[A" for the up arrow.
+	if len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O') {
+		switch input[2] {
+		case 'A':
+			return keyPress{key: keyUp}, nil
+		case 'B':
+			return keyPress{key: keyDown}, nil
+		case 'C':
+			return keyPress{key: keyRight}, nil
+		case 'D':
+			return keyPress{key: keyLeft}, nil
+		case 'Z':
+			return keyPress{key: keyBackTab}, nil
+		}
+
+		return keyPress{key: keyUnknown}, nil
+	}
+
+	switch input[0] {
+	case 0x1b:
+		return keyPress{key: keyEscape}, nil
+	case 0x03:
+		return keyPress{key: keyInterrupt}, nil
+	case '\t':
+		return keyPress{key: keyTab}, nil
+	case '\r', '\n':
+		return keyPress{key: keyEnter}, nil
+	case 0x7f, 0x08:
+		return keyPress{key: keyBackspace}, nil
+	}
+
+	return keyPress{key: keyChar, char: rune(input[0])}, nil
+}
+
+// Returns a renderer of boards printed to the standard output: in colors with outlines of pieces in a terminal,
+// in plain ASCII otherwise.
+func getRenderer() klotski.Renderer {
+	if isTerminal(os.Stdout) {
+		return klotski.ANSIRenderer{}
+	}
+
+	return klotski.TextRenderer{}
+}
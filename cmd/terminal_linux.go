@@ -0,0 +1,57 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Puts the terminal of a file into raw mode, where keys are read one by one without echo, and returns
+// a function restoring its previous mode.
+func makeRaw(file *os.File) (func(), error) {
+	old, err := getTermios(file)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := setTermios(file, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() { setTermios(file, old) }, nil
+}
+
+// Checks if a file is a terminal.
+func isTerminal(file *os.File) bool {
+	_, err := getTermios(file)
+
+	return err == nil
+}
+
+// Returns settings of the terminal of a file.
+func getTermios(file *os.File) (*syscall.Termios, error) {
+	var termios syscall.Termios
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios))); errno != 0 {
+		return nil, errno
+	}
+
+	return &termios, nil
+}
+
+// Changes settings of the terminal of a file.
+func setTermios(file *os.File, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// Puts the terminal of a file into raw mode, which is only supported on Linux.
+func makeRaw(file *os.File) (func(), error) {
+	return nil, errors.New("Raw terminal mode is only supported on Linux")
+}
+
+// Checks if a file is a terminal, which is only known on Linux.
+func isTerminal(file *os.File) bool {
+	return false
+}
@@ -1,5 +1,5 @@
 module github.com/mfiedorowicz/klotski-go
 
-go 1.14
+go 1.18
 
 require github.com/gorilla/mux v1.7.4
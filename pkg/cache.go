@@ -0,0 +1,248 @@
+package klotski
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MoveMetric is the metric of moves counted by searches: a piece sliding by one or two spaces in the same direction is a single move.
+// It is a part of canonical hashes, so solutions cached for a different metric are never reused.
+const MoveMetric = "slide-1-2"
+
+// Version of the canonical hash, bumped whenever hashes of the same puzzle change.
+const canonicalHashVersion = 1
+
+// SolutionCache caches solutions in memory, evicting least recently used ones when it is full,
+// and optionally in a directory, where they survive restarts. It is safe to share across goroutines.
+//
+// Solutions are keyed by canonical hashes of puzzles and stored as positions of moved pieces,
+// so a solution is reused for any board with the same layout, regardless of labels of its pieces.
+type SolutionCache struct {
+	size    int
+	dir     string
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	// Keys of the entries, the most recently used first.
+	order *list.List
+}
+
+// CachedMove is a move stored by SolutionCache: the starting block (top left one) of the moved piece
+// before and after the move.
+type CachedMove struct {
+	From Block
+	To   Block
+}
+
+// Entry of the in-memory cache.
+type cacheEntry struct {
+	key   string
+	moves []CachedMove
+}
+
+// NewSolutionCache returns a cache of at most a given number of solutions in memory.
+// If dir is not empty, solutions are also stored in files of that directory, created if needed.
+func NewSolutionCache(size int, dir string) (*SolutionCache, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("Cannot create cache directory: %s", err)
+		}
+	}
+
+	cache := &SolutionCache{
+		size:    size,
+		dir:     dir,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+
+	return cache, nil
+}
+
+// CanonicalHash returns a hash identifying a puzzle starting from a given state, stable across runs:
+// the size of the board, positions and sizes of pieces, the goal and the metric of moves.
+// Pieces of the same size (other than the goal piece) are interchangeable, so their labels are not a part of it.
+func (board *Board) CanonicalHash(state State) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("v%d %s %dx%d goal %d %d\n", canonicalHashVersion, MoveMetric, board.Width, board.Height, board.Goal.X, board.Goal.Y))
+
+	anchors := make(map[Block]string, len(state.Pieces))
+
+	for _, piece := range state.Pieces {
+		startingBlock, _ := state.getPieceStartingBlock(piece)
+		anchors[startingBlock] = fmt.Sprintf("%dx%d", piece.Width, piece.Height)
+
+		if piece.Label == board.Goal.Label {
+			anchors[startingBlock] = "*" + anchors[startingBlock]
+		}
+	}
+
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			if anchor, ok := anchors[Block{X: x, Y: y}]; ok {
+				buffer.WriteString(anchor)
+			} else {
+				buffer.WriteString(".")
+			}
+
+			buffer.WriteString(" ")
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	hash := sha256.Sum256(buffer.Bytes())
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Get returns moves of a solution cached for a given key. Unreadable files are treated as missing solutions.
+func (cache *SolutionCache) Get(key string) ([]CachedMove, bool) {
+	cache.mutex.Lock()
+
+	if element, ok := cache.entries[key]; ok {
+		cache.order.MoveToFront(element)
+		cache.mutex.Unlock()
+
+		return element.Value.(*cacheEntry).moves, true
+	}
+
+	cache.mutex.Unlock()
+
+	if cache.dir == "" {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(cache.getPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var moves []CachedMove
+
+	if err := json.Unmarshal(content, &moves); err != nil {
+		return nil, false
+	}
+
+	cache.add(key, moves)
+
+	return moves, true
+}
+
+// Put caches moves of a solution for a given key. Returns an error if the solution cannot be stored in a file,
+// in which case it is still cached in memory.
+func (cache *SolutionCache) Put(key string, moves []CachedMove) error {
+	cache.add(key, moves)
+
+	if cache.dir == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(moves)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(cache.dir, key+".tmp")
+	if err != nil {
+		return fmt.Errorf("Cannot cache solution: %s", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("Cannot cache solution: %s", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("Cannot cache solution: %s", err)
+	}
+
+	if err := os.Rename(file.Name(), cache.getPath(key)); err != nil {
+		return fmt.Errorf("Cannot cache solution: %s", err)
+	}
+
+	return nil
+}
+
+// Len returns the number of solutions cached in memory.
+func (cache *SolutionCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.order.Len()
+}
+
+// Adds moves to the in-memory cache, evicting the least recently used entry if it is full.
+func (cache *SolutionCache) add(key string, moves []CachedMove) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value.(*cacheEntry).moves = moves
+		cache.order.MoveToFront(element)
+
+		return
+	}
+
+	cache.entries[key] = cache.order.PushFront(&cacheEntry{key: key, moves: moves})
+
+	if cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Returns path of a file storing a solution for a given key.
+func (cache *SolutionCache) getPath(key string) string {
+	return filepath.Join(cache.dir, key+".json")
+}
+
+// Returns moves of a solution in the form stored by the cache.
+func getCachedMoves(moves []Move) []CachedMove {
+	cachedMoves := make([]CachedMove, len(moves))
+
+	for idx, move := range moves {
+		from, _ := move.Before.getPieceStartingBlock(move.Piece)
+		to := Block{X: from.X + move.Direction.X*move.Distance, Y: from.Y + move.Direction.Y*move.Distance}
+
+		cachedMoves[idx] = CachedMove{From: from, To: to}
+	}
+
+	return cachedMoves
+}
+
+// Replays cached moves from a given state. Returns an error if any of them is not possible or they do not solve
+// the puzzle, i.e. when they are truncated.
+func (board *Board) replayCachedMoves(state State, cachedMoves []CachedMove) ([]Move, error) {
+	moves := make([]Move, 0, len(cachedMoves))
+
+	for _, cachedMove := range cachedMoves {
+		move, err := board.getMove(state, cachedMove.From, cachedMove.To)
+		if err != nil {
+			return nil, err
+		}
+
+		moves = append(moves, move)
+		state = move.After
+	}
+
+	if !board.IsSolved(state) {
+		return nil, fmt.Errorf("Cached moves do not solve the puzzle")
+	}
+
+	return moves, nil
+}
@@ -0,0 +1,142 @@
+package klotski
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalHash(t *testing.T) {
+	board := initBoard()
+	hash := board.CanonicalHash(board.State)
+
+	other := initBoard()
+
+	if hash != other.CanonicalHash(other.State) {
+		t.Error("Canonical hash differs between boards of the same puzzle.")
+	}
+
+	// The same layout with pieces of the same size swapped has the same hash.
+	puzzle, _ := ParsePuzzle("goal: b 1 3\n\na b b c\na b b c\nd e e f\nd h g f\nj . . i\n")
+
+	if swapped := puzzle.Board(); swapped.CanonicalHash(swapped.State) != hash {
+		t.Error("Canonical hash differs for swapped pieces of the same size.")
+	}
+
+	pieceMoves, _ := ParseMoves("jL")
+	states, _ := board.ApplyMoves(board.State, pieceMoves)
+
+	if board.CanonicalHash(states[0]) == hash {
+		t.Error("Canonical hash does not differ for different states.")
+	}
+
+	goalBoard := NewBoard(board.Width, board.Height, board.State, Goal{Label: "b", X: 1, Y: 2})
+
+	if goalBoard.CanonicalHash(goalBoard.State) == hash {
+		t.Error("Canonical hash does not differ for different goals.")
+	}
+}
+
+func TestSolveCached(t *testing.T) {
+	cache, _ := NewSolutionCache(10, "")
+	board := initBoard()
+
+	solution, err := board.SolveContext(context.Background(), SolveOptions{Cache: cache})
+
+	if err != nil || solution.Cached || cache.Len() != 1 {
+		t.Fatalf("Solution not cached, got: %v, cached: %v, cache size: %d", err, solution.Cached, cache.Len())
+	}
+
+	// A different board of the same puzzle, with different hashes and labels of interchangeable pieces.
+	puzzle, _ := ParsePuzzle("goal: b 1 3\n\na b b c\na b b c\nd e e f\nd h g f\nj . . i\n")
+	swapped := puzzle.Board()
+
+	cached, err := swapped.SolveContext(context.Background(), SolveOptions{Cache: cache, MaxNodes: 1})
+
+	if err != nil || !cached.Cached {
+		t.Fatalf("Cached solution not used, got: %v, cached: %v", err, cached.Cached)
+	}
+
+	if len(cached.Moves) != len(solution.Moves) || !cached.Moves[len(cached.Moves)-1].After.isFinal(swapped.Goal) {
+		t.Errorf("Incorrect cached solution, got: %d moves, want: %d", len(cached.Moves), len(solution.Moves))
+	}
+
+	if cached.Moves[0].Before.Hash != swapped.State.Hash {
+		t.Error("Cached solution does not start from the state of the board.")
+	}
+}
+
+func TestSolutionCacheEviction(t *testing.T) {
+	cache, _ := NewSolutionCache(2, "")
+
+	cache.Put("a", []CachedMove{})
+	cache.Put("b", []CachedMove{})
+	cache.Get("a")
+	cache.Put("c", []CachedMove{})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Least recently used solution not evicted.")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Solution %s evicted.", key)
+		}
+	}
+}
+
+func TestSolutionCacheFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	cache, err := NewSolutionCache(1, dir)
+
+	if err != nil {
+		t.Fatalf("Cannot create cache, got: %v", err)
+	}
+
+	board := initBoard()
+	solution, _ := board.SolveContext(context.Background(), SolveOptions{Cache: cache})
+
+	// A new cache in the same directory behaves like a restarted application.
+	restarted, _ := NewSolutionCache(1, dir)
+	cached, err := board.SolveContext(context.Background(), SolveOptions{Cache: restarted, MaxNodes: 1})
+
+	if err != nil || !cached.Cached || FormatMoves(Notation(cached.Moves)) != FormatMoves(Notation(solution.Moves)) {
+		t.Errorf("Solution not restored from files, got: %v, cached: %v", err, cached.Cached)
+	}
+
+	// A damaged file is ignored and overwritten.
+	key := board.CanonicalHash(board.State)
+	os.WriteFile(filepath.Join(dir, key+".json"), []byte("[{\"From\":{\"X\":0,\"Y\":0},\"To\":{\"X\":0,\"Y\":1}}]"), 0644)
+
+	damaged, _ := NewSolutionCache(1, dir)
+	resolved, err := board.SolveContext(context.Background(), SolveOptions{Cache: damaged})
+
+	if err != nil || resolved.Cached || len(resolved.Moves) != len(solution.Moves) {
+		t.Errorf("Damaged cache file not ignored, got: %v, cached: %v", err, resolved.Cached)
+	}
+}
+
+func TestSolutionCacheUnsolved(t *testing.T) {
+	board := initBoard()
+	key := board.CanonicalHash(board.State)
+
+	// Legal moves which do not solve the puzzle: none at all and the first move of piece i only.
+	for _, content := range []string{"[]", "[{\"From\":{\"X\":0,\"Y\":4},\"To\":{\"X\":1,\"Y\":4}}]"} {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, key+".json"), []byte(content), 0644)
+
+		cache, _ := NewSolutionCache(1, dir)
+		solution, err := board.SolveContext(context.Background(), SolveOptions{Cache: cache})
+
+		if err != nil || solution.Cached || len(solution.Moves) != 90 {
+			t.Errorf("Unsolved cache entry %s not ignored, got: %d moves, %v, cached: %v", content, len(solution.Moves), err, solution.Cached)
+		}
+
+		restarted, _ := NewSolutionCache(1, dir)
+
+		if moves, ok := restarted.Get(key); !ok || len(moves) != 90 {
+			t.Errorf("Unsolved cache entry %s not overwritten, got: %d moves", content, len(moves))
+		}
+	}
+}
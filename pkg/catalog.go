@@ -0,0 +1,75 @@
+package klotski
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Puzzles of the built-in catalog, one file per puzzle in the text grid format.
+//
+//go:embed puzzles/*.txt
+var catalogFiles embed.FS
+
+// Catalog returns all puzzles of the built-in catalog sorted by name.
+func Catalog() ([]Puzzle, error) {
+	entries, err := catalogFiles.ReadDir("puzzles")
+	if err != nil {
+		return nil, err
+	}
+
+	puzzles := make([]Puzzle, 0, len(entries))
+
+	for _, entry := range entries {
+		content, err := catalogFiles.ReadFile(path.Join("puzzles", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		puzzle, err := ParsePuzzle(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid catalog puzzle %s: %s", entry.Name(), err)
+		}
+
+		puzzles = append(puzzles, puzzle)
+	}
+
+	sortPuzzles(puzzles)
+
+	return puzzles, nil
+}
+
+// FindPuzzle returns a puzzle from the built-in catalog by its name, i.e. "Heng Dao Li Ma" or "heng-dao-li-ma".
+func FindPuzzle(name string) (Puzzle, error) {
+	puzzles, err := Catalog()
+	if err != nil {
+		return Puzzle{}, err
+	}
+
+	for _, puzzle := range puzzles {
+		if strings.EqualFold(puzzle.Name, name) || slug(puzzle.Name) == slug(name) {
+			return puzzle, nil
+		}
+	}
+
+	return Puzzle{}, fmt.Errorf("Cannot find puzzle %q in the catalog", name)
+}
+
+// FindPuzzlesByTag returns all puzzles from the built-in catalog tagged with a given tag.
+func FindPuzzlesByTag(tag string) ([]Puzzle, error) {
+	puzzles, err := Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := make([]Puzzle, 0)
+
+	for _, puzzle := range puzzles {
+		if puzzle.HasTag(tag) {
+			tagged = append(tagged, puzzle)
+		}
+	}
+
+	return tagged, nil
+}
@@ -0,0 +1,147 @@
+package klotski
+
+import (
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	puzzles, err := Catalog()
+
+	if err != nil {
+		t.Fatalf("Cannot read catalog, got: %v", err)
+	}
+
+	if len(puzzles) == 0 {
+		t.Fatal("Catalog is empty.")
+	}
+
+	for _, puzzle := range puzzles {
+		if puzzle.Moves == 0 {
+			t.Errorf("Puzzle %s has no known number of moves", puzzle.Name)
+			continue
+		}
+
+		board := puzzle.Board()
+		results, err := board.Solve()
+
+		if err != nil {
+			t.Errorf("Puzzle %s not solved, got: %v", puzzle.Name, err)
+		} else if len(results) != puzzle.Moves {
+			t.Errorf("Puzzle %s solved in incorrect number of moves, got: %d, want: %d", puzzle.Name, len(results), puzzle.Moves)
+		}
+	}
+}
+
+// Numbers of moves of catalog puzzles when any path of a single piece counts as one move, the way published solutions
+// count them. Their sources are given in files of the puzzles.
+var publishedMoves = map[string]int{
+	"Bing Fen San Lu":  72,
+	"Heng Dao Li Ma":   81,
+	"Pennant":          59,
+	"Qi Tou Bing Jin":  60,
+	"Zhi Hui Ruo Ding": 70,
+}
+
+// Checks layouts of the catalog against published numbers of moves, counted by a search independent
+// of the solver and its metric.
+func TestCatalogPublishedMoves(t *testing.T) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		want, ok := publishedMoves[puzzle.Name]
+		if !ok {
+			t.Errorf("Puzzle %s has no published number of moves", puzzle.Name)
+			continue
+		}
+
+		board := puzzle.Board()
+
+		if moves := countPathMoves(&board); moves != want {
+			t.Errorf("Puzzle %s solved in incorrect number of moves of any path, got: %d, want: %d", puzzle.Name, moves, want)
+		}
+	}
+}
+
+func TestFindPuzzle(t *testing.T) {
+	for _, name := range []string{"Heng Dao Li Ma", "heng dao li ma", "heng-dao-li-ma"} {
+		puzzle, err := FindPuzzle(name)
+
+		if err != nil || puzzle.Name != "Heng Dao Li Ma" {
+			t.Errorf("Puzzle %q not found, got: %+v, %v", name, puzzle.Name, err)
+		}
+	}
+
+	if _, err := FindPuzzle("Unknown"); err == nil {
+		t.Error("Error not returned for unknown puzzle.")
+	}
+}
+
+func TestFindPuzzlesByTag(t *testing.T) {
+	puzzles, err := FindPuzzlesByTag("huarongdao")
+
+	if err != nil || len(puzzles) < 4 {
+		t.Errorf("Incorrect number of puzzles tagged huarongdao, got: %d, %v", len(puzzles), err)
+	}
+
+	puzzles, _ = FindPuzzlesByTag("unknown")
+
+	if len(puzzles) != 0 {
+		t.Errorf("Incorrect number of puzzles tagged unknown, got: %d, want: 0", len(puzzles))
+	}
+}
+
+// Returns the optimal number of moves to solve a board when any path of a single piece counts as one move,
+// with a breadth-first search over single steps of pieces, or -1 if it cannot be solved.
+func countPathMoves(board *Board) int {
+	generator := board.newMoveGenerator()
+	visited := map[int]bool{board.State.Hash: true}
+	layer := []State{board.State}
+
+	for depth := 0; len(layer) > 0; depth++ {
+		var next []State
+
+		for _, state := range layer {
+			if board.IsSolved(state) {
+				return depth
+			}
+
+			for pieceIdx := range state.Pieces {
+				// States reachable by single steps of the piece alone are a single move away.
+				reached := map[int]bool{state.Hash: true}
+				queue := []State{state}
+
+				for len(queue) > 0 {
+					current := queue[0]
+					queue = queue[1:]
+					generator.load(current)
+
+					for _, move := range directions {
+						piece := current.Pieces[pieceIdx]
+
+						if !generator.isFree(piece, generator.anchors[pieceIdx], move, 1) {
+							continue
+						}
+
+						after := board.movePiece(current, pieceIdx, piece, move, 1)
+
+						if reached[after.Hash] {
+							continue
+						}
+
+						reached[after.Hash] = true
+						queue = append(queue, after)
+
+						if !visited[after.Hash] {
+							visited[after.Hash] = true
+							next = append(next, after)
+						}
+					}
+				}
+			}
+		}
+
+		layer = next
+	}
+
+	return -1
+}
@@ -0,0 +1,273 @@
+package klotski
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version of the checkpoint format, bumped on incompatible changes.
+const checkpointVersion = 2
+
+// Checkpoint holds everything needed to resume a search: parameters of the board,
+// states of the search (with links to states they have been reached from) and visited states.
+type checkpoint struct {
+	Version     int
+	Width       int
+	Height      int
+	Goal        Goal
+	ZobristHash [][][]int
+	Pieces      []Piece
+	Expanded    int
+	Elapsed     time.Duration
+	States      []checkpointState
+	Visited     []int
+}
+
+// Compact representation of a search state and its link. Pieces are stored as starting blocks
+// in the order of the initial state.
+type checkpointState struct {
+	Blocks    []Block
+	Hash      int
+	Parent    int
+	PieceIdx  int
+	Direction Direction
+	Distance  int
+	Depth     int
+}
+
+// SaveCheckpoint saves the board and the progress of the search to a file, which can be resumed with LoadCheckpoint.
+func (search *Search) SaveCheckpoint(path string) error {
+	board := search.Board
+
+	cp := checkpoint{
+		Version:     checkpointVersion,
+		Width:       board.Width,
+		Height:      board.Height,
+		Goal:        board.Goal,
+		ZobristHash: board.ZobristHash,
+		Pieces:      board.State.Pieces,
+		Expanded:    search.expanded,
+		Elapsed:     search.elapsed,
+		States:      make([]checkpointState, len(search.States)),
+		Visited:     make([]int, 0, len(search.VisitedStatesHashes)),
+	}
+
+	for idx, state := range search.States {
+		cp.States[idx] = cp.newCheckpointState(state, search.links[idx])
+	}
+
+	for hash := range search.VisitedStatesHashes {
+		cp.Visited = append(cp.Visited, hash)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("Cannot save checkpoint: %s", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	if err := gob.NewEncoder(file).Encode(cp); err != nil {
+		file.Close()
+		return fmt.Errorf("Cannot save checkpoint: %s", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("Cannot save checkpoint: %s", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("Cannot save checkpoint: %s", err)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint loads a search saved with SaveCheckpoint. Calling SolveContext on the search resumes it.
+func LoadCheckpoint(path string) (*Search, error) {
+	var cp checkpoint
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot load checkpoint: %s", err)
+	}
+
+	defer file.Close()
+
+	if err := gob.NewDecoder(file).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("Cannot load checkpoint: %s", err)
+	}
+
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("Cannot load checkpoint: unsupported version %d, want: %d", cp.Version, checkpointVersion)
+	}
+
+	if err := cp.validate(); err != nil {
+		return nil, fmt.Errorf("Cannot load checkpoint: %s", err)
+	}
+
+	board := &Board{
+		Width:       cp.Width,
+		Height:      cp.Height,
+		Goal:        cp.Goal,
+		ZobristHash: cp.ZobristHash,
+		State:       State{Pieces: cp.Pieces},
+	}
+
+	board.State.Hash = board.GetZobristHash(board.State)
+
+	search := &Search{
+		Board:               board,
+		States:              make([]State, 0, len(cp.States)),
+		VisitedStatesHashes: make(map[int]bool, len(cp.Visited)),
+		links:               make([]stateLink, 0, len(cp.States)),
+		expanded:            cp.Expanded,
+		elapsed:             cp.Elapsed,
+	}
+
+	for _, cpState := range cp.States {
+		search.States = append(search.States, cp.getState(cpState))
+		search.links = append(search.links, stateLink{
+			parent:    cpState.Parent,
+			pieceIdx:  cpState.PieceIdx,
+			direction: cpState.Direction,
+			distance:  cpState.Distance,
+			depth:     cpState.Depth,
+		})
+	}
+
+	for _, hash := range cp.Visited {
+		search.VisitedStatesHashes[hash] = true
+	}
+
+	return search, nil
+}
+
+// Returns an error if the decoded checkpoint is inconsistent, i.e. states or links refer to pieces, spaces
+// or other states which do not exist, so the resumed search would fail.
+func (cp *checkpoint) validate() error {
+	if cp.Width < 1 || cp.Height < 1 || cp.Width > MaxBoardWidth || cp.Height > MaxBoardHeight {
+		return fmt.Errorf("invalid size of the board %dx%d", cp.Width, cp.Height)
+	}
+
+	if len(cp.ZobristHash) != cp.Height {
+		return fmt.Errorf("invalid Zobrist hash of the board")
+	}
+
+	for _, row := range cp.ZobristHash {
+		if len(row) != cp.Width {
+			return fmt.Errorf("invalid Zobrist hash of the board")
+		}
+
+		for _, values := range row {
+			if len(values) != cp.Width*cp.Height+2 {
+				return fmt.Errorf("invalid Zobrist hash of the board")
+			}
+		}
+	}
+
+	for _, piece := range cp.Pieces {
+		if piece.Width < 1 || piece.Height < 1 || len(piece.Blocks) != piece.Width*piece.Height {
+			return fmt.Errorf("invalid piece %s", piece.Label)
+		}
+	}
+
+	board := &Board{Width: cp.Width, Height: cp.Height, State: State{Pieces: cp.Pieces}}
+
+	if err := board.validateState(board.State); err != nil {
+		return err
+	}
+
+	if len(cp.States) == 0 || cp.Expanded < 0 || cp.Expanded > len(cp.States) {
+		return fmt.Errorf("%d states expanded out of %d", cp.Expanded, len(cp.States))
+	}
+
+	for idx, cpState := range cp.States {
+		if len(cpState.Blocks) != len(cp.Pieces) {
+			return fmt.Errorf("state %d has %d pieces, want: %d", idx, len(cpState.Blocks), len(cp.Pieces))
+		}
+
+		for pieceIdx, block := range cpState.Blocks {
+			piece := cp.Pieces[pieceIdx]
+
+			if block.X < 0 || block.Y < 0 || block.X+piece.Width > cp.Width || block.Y+piece.Height > cp.Height {
+				return fmt.Errorf("piece %s of state %d is outside of the board", piece.Label, idx)
+			}
+		}
+
+		if idx == 0 {
+			if cpState.Parent != -1 || cpState.Depth != 0 {
+				return fmt.Errorf("state %d is not the initial state", idx)
+			}
+
+			continue
+		}
+
+		if cpState.Parent < 0 || cpState.Parent >= idx || cpState.PieceIdx < 0 || cpState.PieceIdx >= len(cp.Pieces) ||
+			cpState.Depth != cp.States[cpState.Parent].Depth+1 {
+			return fmt.Errorf("invalid move leading to state %d", idx)
+		}
+	}
+
+	return nil
+}
+
+// Puzzle returns the puzzle solved by a search, i.e. one loaded from a checkpoint: the size and goal of its board
+// and its initial state. Names, tags and numbers of moves are not saved in checkpoints.
+func (search *Search) Puzzle() Puzzle {
+	initialState := search.Board.State
+
+	if len(search.States) > 0 {
+		initialState = search.States[0]
+	}
+
+	pieces := make([]Piece, len(initialState.Pieces))
+	copy(pieces, initialState.Pieces)
+
+	return Puzzle{Width: search.Board.Width, Height: search.Board.Height, Goal: search.Board.Goal, Pieces: pieces}
+}
+
+// Returns compact representation of a board state and its link.
+func (cp *checkpoint) newCheckpointState(state State, link stateLink) checkpointState {
+	cpState := checkpointState{
+		Blocks:    make([]Block, len(state.Pieces)),
+		Hash:      state.Hash,
+		Parent:    link.parent,
+		PieceIdx:  link.pieceIdx,
+		Direction: link.direction,
+		Distance:  link.distance,
+		Depth:     link.depth,
+	}
+
+	for idx, piece := range state.Pieces {
+		cpState.Blocks[idx], _ = state.getPieceStartingBlock(piece)
+	}
+
+	return cpState
+}
+
+// Returns a search state from its compact representation.
+func (cp *checkpoint) getState(cpState checkpointState) State {
+	state := State{
+		Pieces: make([]Piece, len(cp.Pieces)),
+		Hash:   cpState.Hash,
+	}
+
+	for idx, piece := range cp.Pieces {
+		startingBlock := cpState.Blocks[idx]
+		blocks := make([]Block, 0, piece.Width*piece.Height)
+
+		for y := 0; y < piece.Height; y++ {
+			for x := 0; x < piece.Width; x++ {
+				blocks = append(blocks, Block{X: startingBlock.X + x, Y: startingBlock.Y + y})
+			}
+		}
+
+		state.Pieces[idx] = Piece{Label: piece.Label, Width: piece.Width, Height: piece.Height, Blocks: blocks}
+	}
+
+	return state
+}
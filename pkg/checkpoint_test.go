@@ -0,0 +1,221 @@
+package klotski
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.checkpoint")
+
+	board := initBoard()
+	expected, err := board.SolveContext(context.Background(), SolveOptions{})
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	interrupted, _ := board.NewSearch(board.State)
+	_, err = interrupted.SolveContext(context.Background(), SolveOptions{MaxNodes: 5000, Checkpoint: path})
+
+	if !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	resumed, err := LoadCheckpoint(path)
+
+	if err != nil {
+		t.Fatalf("Cannot load checkpoint, got: %v", err)
+	}
+
+	if len(resumed.States) != len(interrupted.States) || len(resumed.VisitedStatesHashes) != len(interrupted.VisitedStatesHashes) {
+		t.Errorf("Checkpoint loaded incorrectly, got: %d states, %d visited, want: %d states, %d visited",
+			len(resumed.States), len(resumed.VisitedStatesHashes), len(interrupted.States), len(interrupted.VisitedStatesHashes))
+	}
+
+	solution, err := resumed.SolveContext(context.Background(), SolveOptions{})
+
+	if err != nil {
+		t.Fatalf("Final state not found after resuming, got: %v", err)
+	}
+
+	got := FormatMoves(Notation(solution.Moves))
+	want := FormatMoves(Notation(expected.Moves))
+
+	if got != want {
+		t.Errorf("Resumed search found a different solution, got: %s, want: %s", got, want)
+	}
+
+	if solution.Stats.NodesExpanded != expected.Stats.NodesExpanded {
+		t.Errorf("Incorrect number of expanded states, got: %d, want: %d", solution.Stats.NodesExpanded, expected.Stats.NodesExpanded)
+	}
+}
+
+func TestCheckpointPuzzle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.checkpoint")
+
+	// A board of another size than the default puzzle, so a report of the wrong puzzle cannot be built.
+	puzzle, err := ParsePuzzle("goal: a 3 0\n\na a b . .\n. . b . .\nc c . . .\n")
+	if err != nil {
+		t.Fatalf("Cannot parse puzzle, got: %v", err)
+	}
+
+	board := puzzle.Board()
+	interrupted, _ := board.NewSearch(board.State)
+
+	if _, err := interrupted.SolveContext(context.Background(), SolveOptions{MaxNodes: 1, Checkpoint: path}); !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	resumed, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Cannot load checkpoint, got: %v", err)
+	}
+
+	resumedPuzzle := resumed.Puzzle()
+
+	if resumedPuzzle.String() != puzzle.String() {
+		t.Errorf("Incorrect puzzle of the checkpoint, got:\n%s\nwant:\n%s", resumedPuzzle.String(), puzzle.String())
+	}
+
+	solution, err := resumed.SolveContext(context.Background(), SolveOptions{})
+	if err != nil {
+		t.Fatalf("Final state not found after resuming, got: %v", err)
+	}
+
+	report := NewReport(resumedPuzzle, solution)
+
+	if len(report.Moves) != len(solution.Moves) || len(report.Moves[0].Board) != 3 {
+		t.Errorf("Incorrect report of the resumed search, got: %+v", report)
+	}
+}
+
+func TestCheckpointInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.checkpoint")
+	interval := time.Millisecond
+	waited, saved := false, false
+
+	opts := SolveOptions{
+		Checkpoint:         path,
+		CheckpointInterval: interval,
+		// The search stops after a few reports of progress, which is saved in a checkpoint either way.
+		MaxNodes: 3 * contextCheckInterval,
+		// Progress is reported right before the interval of checkpoints is checked, so waiting for the interval
+		// on the first report saves a checkpoint however fast the search is.
+		ProgressInterval: time.Nanosecond,
+		Progress: func(Stats) {
+			if !waited {
+				waited = true
+				time.Sleep(interval)
+				return
+			}
+
+			if _, err := os.Stat(path); err == nil {
+				saved = true
+			}
+		},
+	}
+
+	board := initBoard()
+	_, err := board.SolveContext(context.Background(), opts)
+
+	if !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	if !saved {
+		t.Error("Checkpoint not saved before reaching the limit.")
+	}
+
+	if search, err := LoadCheckpoint(path); err != nil || search.expanded != opts.MaxNodes {
+		t.Errorf("Checkpoint not saved on reaching the limit, got: %v", err)
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp*")
+
+	if len(matches) != 0 {
+		t.Errorf("Temporary files left behind, got: %v", matches)
+	}
+}
+
+func TestLoadCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "invalid.checkpoint")
+	os.WriteFile(path, []byte("not a checkpoint"), 0644)
+
+	for _, p := range []string{path, path + ".missing"} {
+		if _, err := LoadCheckpoint(p); err == nil {
+			t.Errorf("Error not returned for checkpoint %s", p)
+		}
+	}
+
+	saved := filepath.Join(dir, "search.checkpoint")
+	board := initBoard()
+
+	if _, err := board.SolveContext(context.Background(), SolveOptions{Checkpoint: saved, MaxNodes: 100}); !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cp *checkpoint)
+	}{
+		{name: "expanded out of range", modify: func(cp *checkpoint) { cp.Expanded = len(cp.States) + 1 }},
+		{name: "negative expanded", modify: func(cp *checkpoint) { cp.Expanded = -1 }},
+		{name: "no states", modify: func(cp *checkpoint) { cp.States, cp.Expanded = nil, 0 }},
+		{name: "missing piece of a state", modify: func(cp *checkpoint) { cp.States[1].Blocks = cp.States[1].Blocks[1:] }},
+		{name: "piece outside of the board", modify: func(cp *checkpoint) { cp.States[1].Blocks[0] = Block{X: cp.Width, Y: 0} }},
+		{name: "parent out of range", modify: func(cp *checkpoint) { cp.States[1].Parent = len(cp.States) }},
+		{name: "moved piece out of range", modify: func(cp *checkpoint) { cp.States[1].PieceIdx = len(cp.Pieces) }},
+		{name: "invalid depth", modify: func(cp *checkpoint) { cp.States[1].Depth = 5 }},
+		{name: "missing Zobrist hash", modify: func(cp *checkpoint) { cp.ZobristHash = cp.ZobristHash[1:] }},
+		{name: "piece without blocks", modify: func(cp *checkpoint) { cp.Pieces[0].Blocks = nil }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cp checkpoint
+
+			file, err := os.Open(saved)
+			if err != nil {
+				t.Fatalf("Cannot open checkpoint, got: %v", err)
+			}
+
+			err = gob.NewDecoder(file).Decode(&cp)
+			file.Close()
+
+			if err != nil {
+				t.Fatalf("Cannot decode checkpoint, got: %v", err)
+			}
+
+			test.modify(&cp)
+
+			path := filepath.Join(t.TempDir(), "modified.checkpoint")
+
+			file, err = os.Create(path)
+			if err != nil {
+				t.Fatalf("Cannot create checkpoint, got: %v", err)
+			}
+
+			err = gob.NewEncoder(file).Encode(cp)
+			file.Close()
+
+			if err != nil {
+				t.Fatalf("Cannot encode checkpoint, got: %v", err)
+			}
+
+			if _, err := LoadCheckpoint(path); err == nil {
+				t.Error("Error not returned for an inconsistent checkpoint")
+			}
+		})
+	}
+
+	if _, err := LoadCheckpoint(saved); err != nil {
+		t.Errorf("Cannot load a valid checkpoint, got: %v", err)
+	}
+}
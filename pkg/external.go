@@ -0,0 +1,637 @@
+package klotski
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Number of states buffered in memory by the external search before they are sorted and written to a file.
+var externalBufferSize = 1 << 20
+
+// External search is a breadth-first search keeping each layer of states (all states reachable in the same number
+// of moves) in a sorted file. States of a new layer are buffered, sorted and written to run files, which are merged
+// and deduplicated against two previous layers (moves are reversible, so neighbours of a layer can only be in the
+// previous, the same or the next layer). The path is reconstructed backwards, by looking up neighbours of each state
+// in the previous layer.
+type externalSearch struct {
+	board *Board
+	opts  SolveOptions
+	dir   string
+	// State the search starts from.
+	initial State
+	// Sizes of piece types, the type of a piece is its index + 1, 0 marks an empty space.
+	types    []Block
+	goalType byte
+	start    time.Time
+	stats    Stats
+	// States waiting to be written to a run file, reused between layers.
+	buffer []byte
+}
+
+// State of the external search is encoded as one byte per space of the board, holding the type of a piece which
+// starting block (top left one) is in that space. Pieces of the same type are interchangeable.
+type externalKey []byte
+
+// Solves the board from a given state with the external search.
+func (board *Board) solveExternal(ctx context.Context, state State, opts SolveOptions) (Solution, error) {
+	dir, err := os.MkdirTemp(opts.TempDir, "klotski-external-")
+	if err != nil {
+		return Solution{}, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	search := externalSearch{board: board, opts: opts, dir: dir, initial: state, start: time.Now()}
+	search.initTypes()
+
+	solution, err := search.solve(ctx)
+	solution.Stats = search.getStats()
+
+	if opts.Progress != nil {
+		opts.Progress(solution.Stats)
+	}
+
+	if limitErr, ok := err.(*LimitError); ok {
+		limitErr.Stats = solution.Stats
+	}
+
+	return solution, err
+}
+
+// Assigns types to pieces of the board, the goal piece has its own type.
+func (search *externalSearch) initTypes() {
+	for _, piece := range search.board.State.Pieces {
+		if piece.Label == search.board.Goal.Label {
+			search.types = append(search.types, Block{X: piece.Width, Y: piece.Height})
+			search.goalType = byte(len(search.types))
+		}
+	}
+
+	for _, piece := range search.board.State.Pieces {
+		if piece.Label != search.board.Goal.Label && search.getType(piece) == 0 {
+			search.types = append(search.types, Block{X: piece.Width, Y: piece.Height})
+		}
+	}
+}
+
+// Returns type of a piece which is not the goal one, 0 if there is no such type yet.
+func (search *externalSearch) getType(piece Piece) byte {
+	for idx, size := range search.types {
+		if byte(idx+1) != search.goalType && size.X == piece.Width && size.Y == piece.Height {
+			return byte(idx + 1)
+		}
+	}
+
+	return 0
+}
+
+// Returns statistics of the search so far.
+func (search *externalSearch) getStats() Stats {
+	stats := search.stats
+	stats.Elapsed = time.Since(search.start)
+
+	return stats
+}
+
+// Returns encoded state of the board.
+func (search *externalSearch) encode(state State) externalKey {
+	key := make(externalKey, search.board.Width*search.board.Height)
+
+	for _, piece := range state.Pieces {
+		startingBlock, _ := state.getPieceStartingBlock(piece)
+		pieceType := search.goalType
+
+		if piece.Label != search.board.Goal.Label {
+			pieceType = search.getType(piece)
+		}
+
+		key[startingBlock.Y*search.board.Width+startingBlock.X] = pieceType
+	}
+
+	return key
+}
+
+// Checks if an encoded state is a final one.
+func (search *externalSearch) isFinal(key externalKey) bool {
+	goal := search.board.Goal
+
+	return key[goal.Y*search.board.Width+goal.X] == search.goalType
+}
+
+// Calls a function for each state reachable in one move, which is moving a piece by one or two spaces
+// in the same direction. The key passed to the function is reused between calls.
+func (search *externalSearch) forEachNeighbour(key externalKey, fn func(externalKey)) {
+	width, height := search.board.Width, search.board.Height
+	occupied := make([]bool, len(key))
+
+	for idx, pieceType := range key {
+		if pieceType == 0 {
+			continue
+		}
+
+		size := search.types[pieceType-1]
+
+		for y := idx / width; y < idx/width+size.Y; y++ {
+			for x := idx % width; x < idx%width+size.X; x++ {
+				occupied[y*width+x] = true
+			}
+		}
+	}
+
+	neighbour := make(externalKey, len(key))
+
+	for idx, pieceType := range key {
+		if pieceType == 0 {
+			continue
+		}
+
+		size := search.types[pieceType-1]
+		x, y := idx%width, idx/width
+
+		for _, move := range getDirections() {
+			for distance := 1; distance <= 2; distance++ {
+				newX, newY := x+move.X*distance, y+move.Y*distance
+
+				if newX < 0 || newY < 0 || newX+size.X > width || newY+size.Y > height {
+					break
+				}
+
+				if !isEnteredSpaceFree(occupied, width, Block{X: newX, Y: newY}, size, move) {
+					break
+				}
+
+				copy(neighbour, key)
+				neighbour[idx] = 0
+				neighbour[newY*width+newX] = pieceType
+				fn(neighbour)
+			}
+		}
+	}
+}
+
+// Checks if spaces entered by a piece of a given size, which moved to a new position, are free.
+func isEnteredSpaceFree(occupied []bool, width int, position Block, size Block, move Direction) bool {
+	if move.X != 0 {
+		x := position.X
+		if move.X > 0 {
+			x += size.X - 1
+		}
+
+		for y := position.Y; y < position.Y+size.Y; y++ {
+			if occupied[y*width+x] {
+				return false
+			}
+		}
+	} else {
+		y := position.Y
+		if move.Y > 0 {
+			y += size.Y - 1
+		}
+
+		for x := position.X; x < position.X+size.X; x++ {
+			if occupied[y*width+x] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Returns path of a layer file.
+func (search *externalSearch) getLayerPath(depth int) string {
+	return filepath.Join(search.dir, fmt.Sprintf("layer-%06d", depth))
+}
+
+// Runs the search, returns a solution without statistics.
+func (search *externalSearch) solve(ctx context.Context) (Solution, error) {
+	keySize := search.board.Width * search.board.Height
+	startKey := search.encode(search.initial)
+
+	if err := os.WriteFile(search.getLayerPath(0), startKey, 0644); err != nil {
+		return Solution{}, err
+	}
+
+	search.stats.Visited, search.stats.FrontierSize = 1, 1
+	lastProgress := time.Now()
+
+	if search.isFinal(startKey) {
+		return Solution{Moves: make([]Move, 0)}, nil
+	}
+
+	for depth := 0; search.stats.FrontierSize > 0; depth++ {
+		search.stats.Depth = depth
+
+		runs, err := search.expandLayer(ctx, depth, keySize, &lastProgress)
+		if err != nil {
+			return Solution{}, err
+		}
+
+		goal, size, err := search.mergeLayer(depth, keySize, runs)
+		if err != nil {
+			return Solution{}, err
+		}
+
+		search.stats.Visited += size
+		search.stats.FrontierSize = size
+
+		if goal != nil {
+			search.stats.Depth = depth + 1
+
+			return search.getSolution(goal, depth+1)
+		}
+	}
+
+	return Solution{Moves: make([]Move, 0)}, ErrNoSolution
+}
+
+// Expands all states of a layer, writes their neighbours to sorted run files. Returns paths of the run files.
+func (search *externalSearch) expandLayer(ctx context.Context, depth, keySize int, lastProgress *time.Time) ([]string, error) {
+	file, err := os.Open(search.getLayerPath(depth))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	key := make(externalKey, keySize)
+	var runs []string
+
+	flush := func() error {
+		if len(search.buffer) == 0 {
+			return nil
+		}
+
+		path := filepath.Join(search.dir, fmt.Sprintf("run-%06d-%06d", depth, len(runs)))
+		if err := writeSortedKeys(path, search.buffer, keySize); err != nil {
+			return err
+		}
+
+		runs = append(runs, path)
+		search.buffer = search.buffer[:0]
+
+		return nil
+	}
+
+	for {
+		if _, err := io.ReadFull(reader, key); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if err := search.board.checkLimits(ctx, search.opts, search.stats.NodesExpanded, uint64(cap(search.buffer))); err != nil {
+			return nil, &LimitError{Err: err}
+		}
+
+		if search.opts.Progress != nil && search.stats.NodesExpanded%contextCheckInterval == 0 && time.Since(*lastProgress) >= search.opts.ProgressInterval {
+			*lastProgress = time.Now()
+			search.opts.Progress(search.getStats())
+		}
+
+		search.forEachNeighbour(key, func(neighbour externalKey) {
+			search.buffer = append(search.buffer, neighbour...)
+		})
+
+		search.stats.NodesExpanded++
+		search.stats.FrontierSize--
+
+		if len(search.buffer) >= externalBufferSize*keySize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return runs, flush()
+}
+
+// Merges run files into the next layer, skipping duplicates and states of the previous two layers.
+// Returns the first final state found in the new layer (if any) and size of the new layer.
+func (search *externalSearch) mergeLayer(depth, keySize int, runs []string) (externalKey, int, error) {
+	var readers []*keyReader
+
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+
+		for _, run := range runs {
+			os.Remove(run)
+		}
+	}()
+
+	open := func(path string) (*keyReader, error) {
+		reader, err := openKeyReader(path, keySize)
+		if err == nil {
+			readers = append(readers, reader)
+		}
+
+		return reader, err
+	}
+
+	merged := &keyHeap{}
+
+	for _, run := range runs {
+		reader, err := open(run)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if reader.Next() {
+			heap.Push(merged, reader)
+		}
+	}
+
+	var previous []*keyReader
+
+	for _, layer := range []int{depth - 1, depth} {
+		if layer < 0 {
+			continue
+		}
+
+		reader, err := open(search.getLayerPath(layer))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		reader.Next()
+		previous = append(previous, reader)
+	}
+
+	file, err := os.Create(search.getLayerPath(depth + 1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	last := make(externalKey, 0, keySize)
+	size := 0
+
+	var goal externalKey
+
+	for merged.Len() > 0 {
+		reader := (*merged)[0]
+		key := reader.Key()
+
+		if len(last) == 0 || !bytes.Equal(key, last) {
+			last = append(last[:0], key...)
+
+			if !containsKey(previous, key) {
+				if _, err := writer.Write(key); err != nil {
+					return nil, 0, err
+				}
+
+				size++
+
+				if goal == nil && search.isFinal(key) {
+					goal = append(externalKey{}, key...)
+				}
+			}
+		}
+
+		if reader.Next() {
+			heap.Fix(merged, 0)
+		} else {
+			heap.Pop(merged)
+		}
+	}
+
+	for _, reader := range readers {
+		if reader.err != nil {
+			return nil, 0, reader.err
+		}
+	}
+
+	return goal, size, writer.Flush()
+}
+
+// Checks if any of the sorted readers contains a key, advancing them up to the key.
+func containsKey(readers []*keyReader, key externalKey) bool {
+	found := false
+
+	for _, reader := range readers {
+		for !reader.done && bytes.Compare(reader.Key(), key) < 0 {
+			reader.Next()
+		}
+
+		if !reader.done && bytes.Equal(reader.Key(), key) {
+			found = true
+		}
+	}
+
+	return found
+}
+
+// Reconstructs the path from the initial state to a final state found at a given depth.
+func (search *externalSearch) getSolution(goal externalKey, depth int) (Solution, error) {
+	keySize := len(goal)
+	keys := []externalKey{goal}
+
+	for layer := depth - 1; layer >= 0; layer-- {
+		file, err := os.Open(search.getLayerPath(layer))
+		if err != nil {
+			return Solution{}, err
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return Solution{}, err
+		}
+
+		var parent externalKey
+		var lookupErr error
+
+		search.forEachNeighbour(keys[len(keys)-1], func(neighbour externalKey) {
+			if parent == nil && lookupErr == nil {
+				var found bool
+				if found, lookupErr = searchSortedKeys(file, int(info.Size())/keySize, neighbour); found {
+					parent = append(externalKey{}, neighbour...)
+				}
+			}
+		})
+
+		file.Close()
+
+		if lookupErr != nil {
+			return Solution{}, lookupErr
+		}
+
+		if parent == nil {
+			return Solution{}, fmt.Errorf("Cannot find state leading to depth %d", layer+1)
+		}
+
+		keys = append(keys, parent)
+	}
+
+	moves := make([]Move, 0, depth)
+	state := search.initial
+
+	for idx := len(keys) - 1; idx > 0; idx-- {
+		move, err := search.getMove(state, keys[idx], keys[idx-1])
+		if err != nil {
+			return Solution{}, err
+		}
+
+		state = move.After
+		moves = append(moves, move)
+	}
+
+	return Solution{Moves: moves}, nil
+}
+
+// Returns a move between two encoded states, made by pieces of a given state (equal to the first one).
+func (search *externalSearch) getMove(state State, from, to externalKey) (Move, error) {
+	var fromIdx, toIdx int
+
+	for idx := range from {
+		if from[idx] != 0 && to[idx] == 0 {
+			fromIdx = idx
+		} else if from[idx] == 0 && to[idx] != 0 {
+			toIdx = idx
+		}
+	}
+
+	width := search.board.Width
+	fromBlock := Block{X: fromIdx % width, Y: fromIdx / width}
+	toBlock := Block{X: toIdx % width, Y: toIdx / width}
+
+	return search.board.getMove(state, fromBlock, toBlock)
+}
+
+// Sorts keys, removes duplicates and writes them to a file.
+func writeSortedKeys(path string, buffer []byte, keySize int) error {
+	keys := make([]externalKey, len(buffer)/keySize)
+
+	for idx := range keys {
+		keys[idx] = buffer[idx*keySize : (idx+1)*keySize]
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+
+	for idx, key := range keys {
+		if idx > 0 && bytes.Equal(key, keys[idx-1]) {
+			continue
+		}
+
+		if _, err := writer.Write(key); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Checks if a file of sorted keys contains a key, using binary search.
+func searchSortedKeys(file *os.File, count int, key externalKey) (bool, error) {
+	record := make(externalKey, len(key))
+
+	var readErr error
+
+	idx := sort.Search(count, func(idx int) bool {
+		if _, err := file.ReadAt(record, int64(idx*len(key))); err != nil {
+			readErr = err
+			return true
+		}
+
+		return bytes.Compare(record, key) >= 0
+	})
+
+	if readErr != nil || idx == count {
+		return false, readErr
+	}
+
+	if _, err := file.ReadAt(record, int64(idx*len(key))); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(record, key), nil
+}
+
+// Reads keys from a file one by one.
+type keyReader struct {
+	file   *os.File
+	reader *bufio.Reader
+	key    externalKey
+	done   bool
+	err    error
+}
+
+func openKeyReader(path string, keySize int) (*keyReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyReader{file: file, reader: bufio.NewReader(file), key: make(externalKey, keySize)}, nil
+}
+
+// Next reads the next key, returns false at the end of the file or on error.
+func (r *keyReader) Next() bool {
+	if r.done {
+		return false
+	}
+
+	if _, err := io.ReadFull(r.reader, r.key); err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+
+		r.done = true
+	}
+
+	return !r.done
+}
+
+// Key returns the current key.
+func (r *keyReader) Key() externalKey {
+	return r.key
+}
+
+// Close closes the file.
+func (r *keyReader) Close() error {
+	return r.file.Close()
+}
+
+// Heap of readers ordered by their current keys, used to merge sorted files.
+type keyHeap []*keyReader
+
+func (h keyHeap) Len() int            { return len(h) }
+func (h keyHeap) Less(i, j int) bool  { return bytes.Compare(h[i].key, h[j].key) < 0 }
+func (h keyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x interface{}) { *h = append(*h, x.(*keyReader)) }
+
+func (h *keyHeap) Pop() interface{} {
+	old := *h
+	reader := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return reader
+}
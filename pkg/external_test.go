@@ -0,0 +1,93 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSolveExternal(t *testing.T) {
+	defer func(size int) { externalBufferSize = size }(externalBufferSize)
+	externalBufferSize = 256
+
+	board := initBoard()
+	tempDir := t.TempDir()
+
+	solution, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmExternal, TempDir: tempDir})
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	if len(solution.Moves) != 90 {
+		t.Errorf("Incorrect number of moves, got: %d, want: %d", len(solution.Moves), 90)
+	}
+
+	if !solution.Moves[len(solution.Moves)-1].After.isFinal(board.Goal) {
+		t.Error("Solution does not reach the final state.")
+	}
+
+	if solution.Stats.Depth != len(solution.Moves) || solution.Stats.NodesExpanded == 0 {
+		t.Errorf("Incorrect statistics, got: %+v", solution.Stats)
+	}
+
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("Temporary files left behind, got: %d", len(entries))
+	}
+}
+
+func TestSolveExternalLargerBoard(t *testing.T) {
+	puzzle, err := ParsePuzzle("goal: a 3 3\n\nb a a c d\nb a a c d\ne f f g h\ni j k g h\ni . . l l\n")
+
+	if err != nil {
+		t.Fatalf("Cannot parse puzzle, got: %v", err)
+	}
+
+	board := puzzle.Board()
+	expected, err := board.Solve()
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	board = puzzle.Board()
+	solution, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmExternal})
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	if len(solution.Moves) != len(expected) {
+		t.Errorf("Incorrect number of moves, got: %d, want: %d", len(solution.Moves), len(expected))
+	}
+}
+
+func TestSolveExternalLimits(t *testing.T) {
+	board := initBoard()
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmExternal, MaxNodes: 100})
+
+	var limitErr *LimitError
+
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrNodeLimit) || limitErr.Stats.NodesExpanded != 100 {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	_, err = board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmExternal, Checkpoint: "checkpoint"})
+
+	if err == nil {
+		t.Error("Error not returned for checkpoint with the external search.")
+	}
+}
+
+func TestSolveExternalUnsolvable(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 1 0\n\na b\n. b\n")
+	board := puzzle.Board()
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmExternal})
+
+	if !errors.Is(err, ErrNoSolution) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrNoSolution)
+	}
+}
@@ -0,0 +1,122 @@
+package klotski
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Solves the board from a given state with a breadth-first search keeping only the layer of states being expanded and the next one.
+// Instead of parents, the depth of each visited state is kept, so the solution can be reconstructed backwards
+// from the final state by choosing any of its neighbours one move closer to the initial state.
+func (board *Board) solveFrontier(ctx context.Context, initial State, opts SolveOptions) (Solution, error) {
+	start := time.Now()
+	lastProgress := start
+
+	depths := map[int]int32{initial.Hash: 0}
+	current := []State{initial}
+	next := make([]State, 0)
+	generator := board.newMoveGenerator()
+	stateSize := board.getStateSize()
+
+	var stats Stats
+
+	getStats := func(idx int) Stats {
+		stats.FrontierSize = len(current) - idx + len(next)
+		stats.Visited = len(depths)
+		stats.Elapsed = time.Since(start)
+
+		return stats
+	}
+
+	finish := func(solution Solution, err error) (Solution, error) {
+		if opts.Progress != nil {
+			opts.Progress(solution.Stats)
+		}
+
+		return solution, err
+	}
+
+	for depth := 0; len(current) > 0; depth++ {
+		stats.Depth = depth
+
+		for idx, state := range current {
+			memory := uint64(len(current)+len(next))*stateSize + uint64(len(depths))*visitedEntrySize
+
+			if err := board.checkLimits(ctx, opts, stats.NodesExpanded, memory); err != nil {
+				stats := getStats(idx)
+
+				return finish(Solution{Stats: stats}, &LimitError{Err: err, Stats: stats})
+			}
+
+			if opts.Progress != nil && stats.NodesExpanded%contextCheckInterval == 0 && time.Since(lastProgress) >= opts.ProgressInterval {
+				lastProgress = time.Now()
+				opts.Progress(getStats(idx))
+			}
+
+			if state.isFinal(board.Goal) {
+				moves, err := board.getFrontierSolution(initial, state, depth, depths)
+
+				return finish(Solution{Moves: moves, Stats: getStats(idx)}, err)
+			}
+
+			generator.forEachNeighbour(state, func(newState State, pieceIdx int, move Direction, distance int) {
+				if _, visited := depths[newState.Hash]; !visited {
+					depths[newState.Hash] = int32(depth + 1)
+					next = append(next, newState)
+				}
+			})
+
+			stats.NodesExpanded++
+		}
+
+		current, next = next, make([]State, 0, len(next))
+	}
+
+	return finish(Solution{Moves: make([]Move, 0), Stats: getStats(0)}, ErrNoSolution)
+}
+
+// Reconstructs moves leading from the initial state to a final state at a given depth, using depths of visited states.
+func (board *Board) getFrontierSolution(initial, finalState State, depth int, depths map[int]int32) ([]Move, error) {
+	// Starting blocks of moved pieces, from the final state backwards.
+	var from, to []Block
+
+	state := finalState
+
+	for d := depth; d > 0; d-- {
+		var previous *State
+
+		board.forEachNeighbour(state, func(newState State, pieceIdx int, move Direction, distance int) {
+			if previousDepth, visited := depths[newState.Hash]; previous == nil && visited && int(previousDepth) == d-1 {
+				startingBlock, _ := state.getPieceStartingBlock(state.Pieces[pieceIdx])
+
+				from = append(from, Block{X: startingBlock.X + move.X*distance, Y: startingBlock.Y + move.Y*distance})
+				to = append(to, startingBlock)
+				previous = &newState
+			}
+		})
+
+		if previous == nil {
+			return nil, fmt.Errorf("Cannot find state leading to depth %d", d)
+		}
+
+		state = *previous
+	}
+
+	// States found backwards may have pieces of the same size swapped, so moves are replayed
+	// from the initial state by positions of moved pieces.
+	moves := make([]Move, 0, depth)
+	state = initial
+
+	for idx := len(from) - 1; idx >= 0; idx-- {
+		move, err := board.getMove(state, from[idx], to[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		state = move.After
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
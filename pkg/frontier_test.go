@@ -0,0 +1,59 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSolveFrontier(t *testing.T) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		board := puzzle.Board()
+		solution, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmFrontier})
+
+		if err != nil {
+			t.Errorf("Puzzle %s not solved, got: %v", puzzle.Name, err)
+			continue
+		}
+
+		if len(solution.Moves) != puzzle.Moves {
+			t.Errorf("Puzzle %s solved in incorrect number of moves, got: %d, want: %d", puzzle.Name, len(solution.Moves), puzzle.Moves)
+		}
+
+		pieceMoves := Notation(solution.Moves)
+		states, err := board.ApplyMoves(board.State, pieceMoves)
+
+		if err != nil || !states[len(states)-1].isFinal(board.Goal) {
+			t.Errorf("Solution of puzzle %s does not reach the final state, got: %v", puzzle.Name, err)
+		}
+
+		if solution.Stats.Depth != puzzle.Moves || solution.Stats.Visited < solution.Stats.NodesExpanded {
+			t.Errorf("Incorrect statistics of puzzle %s, got: %+v", puzzle.Name, solution.Stats)
+		}
+	}
+}
+
+func TestSolveFrontierLimits(t *testing.T) {
+	board := initBoard()
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmFrontier, MaxNodes: 100})
+
+	var limitErr *LimitError
+
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrNodeLimit) || limitErr.Stats.NodesExpanded != 100 {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+}
+
+func TestSolveFrontierUnsolvable(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 1 0\n\na b\n. b\n")
+	board := puzzle.Board()
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: AlgorithmFrontier})
+
+	if !errors.Is(err, ErrNoSolution) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrNoSolution)
+	}
+}
@@ -0,0 +1,209 @@
+package klotski
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func FuzzMoves(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7})
+	f.Add([]byte{255, 128, 64, 32, 16, 8, 4, 2, 1})
+	f.Add([]byte("gD jL fD eR dR iU gL dD eLL hU hR jUU"))
+
+	board := initBoard()
+
+	f.Fuzz(func(t *testing.T, choices []byte) {
+		if err := checkRandomWalk(&board, choices); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func FuzzParsePuzzle(f *testing.F) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		f.Add(puzzle.String())
+	}
+
+	f.Add("goal: a 1 0\n\na b\n. b\n")
+	f.Add("goal: a 0 0\n\na a\na .\n")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		puzzle, err := ParsePuzzle(text)
+		if err != nil {
+			return
+		}
+
+		if puzzle.Width*puzzle.Height > 64 {
+			return
+		}
+
+		board := puzzle.Board()
+
+		if err := board.validateState(board.State); err != nil {
+			t.Errorf("Parsed puzzle has an invalid state, got: %v", err)
+		}
+
+		reparsed, err := ParsePuzzle(puzzle.String())
+
+		if err != nil || reparsed.String() != puzzle.String() {
+			t.Errorf("Puzzle does not round-trip, got: %q, %v, want: %q", reparsed.String(), err, puzzle.String())
+		}
+
+		if err := checkRandomWalk(&board, []byte(text)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func FuzzApplyMoves(f *testing.F) {
+	f.Add("jL iRR")
+	f.Add("gD jL fD eR dR iU gL dD eLL hU hR jUU")
+	f.Add("hUL aDDRR, bRL")
+
+	board := initBoard()
+
+	f.Fuzz(func(t *testing.T, notation string) {
+		pieceMoves, err := ParseMoves(notation)
+		if err != nil {
+			return
+		}
+
+		states, _ := board.ApplyMoves(board.State, pieceMoves)
+
+		for idx, state := range states {
+			if err := board.validateState(state); err != nil {
+				t.Errorf("Move %d of %q leads to an invalid state, got: %v", idx+1, notation, err)
+			}
+
+			if state.Hash != board.GetZobristHash(state) {
+				t.Errorf("Move %d of %q leads to a state with an incorrect hash", idx+1, notation)
+			}
+		}
+	})
+}
+
+func TestRandomWalk(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		board := puzzle.Board()
+		choices := make([]byte, 1000)
+		random.Read(choices)
+
+		if err := checkRandomWalk(&board, choices); err != nil {
+			t.Errorf("Puzzle %s: %v", puzzle.Name, err)
+		}
+	}
+}
+
+func TestSolutionsVerify(t *testing.T) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		board := puzzle.Board()
+
+		for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmFrontier, AlgorithmExternal} {
+			solution, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: algorithm, TempDir: t.TempDir()})
+
+			if err != nil {
+				t.Errorf("Puzzle %s not solved with %s, got: %v", puzzle.Name, algorithm, err)
+				continue
+			}
+
+			if err := verifyMoves(&board, board.State, solution.Moves); err != nil {
+				t.Errorf("Solution of %s found with %s does not verify: %v", puzzle.Name, algorithm, err)
+			} else if !solution.Moves[len(solution.Moves)-1].After.isFinal(board.Goal) {
+				t.Errorf("Solution of %s found with %s does not reach the final state", puzzle.Name, algorithm)
+			}
+
+			if len(solution.Moves) != puzzle.Moves {
+				t.Errorf("Puzzle %s solved with %s in incorrect number of moves, got: %d, want: %d", puzzle.Name, algorithm, len(solution.Moves), puzzle.Moves)
+			}
+		}
+	}
+}
+
+// Walks from the initial state of a board, choosing one of the moves possible from each state by given bytes.
+// Returns an error if any of the moves breaks an invariant of the move engine.
+func checkRandomWalk(board *Board, choices []byte) error {
+	state := board.State
+
+	for step, choice := range choices {
+		moves := board.Moves(state)
+
+		if len(moves) == 0 {
+			return nil
+		}
+
+		move := moves[int(choice)%len(moves)]
+
+		if err := verifyMoves(board, state, []Move{move}); err != nil {
+			return fmt.Errorf("step %d: %s", step+1, err)
+		}
+
+		// Moving the piece back restores the hash of the state before the move.
+		pieceIdx := state.getPieceIndex(move.Piece.Label)
+		inverse := Direction{X: -move.Direction.X, Y: -move.Direction.Y}
+		back := move.After
+
+		for idx := 0; idx < move.Distance; idx++ {
+			back = board.shiftPiece(back, pieceIdx, back.Pieces[pieceIdx], inverse)
+		}
+
+		if back.Hash != state.Hash {
+			return fmt.Errorf("step %d: move %s and its inverse do not restore the hash", step+1, move)
+		}
+
+		state = move.After
+	}
+
+	return nil
+}
+
+// Verifies moves one by one from a given state: each of them has to be legal, lead to a valid state with a hash equal
+// to the full hash of the state, and start from the state after the previous move.
+func verifyMoves(board *Board, start State, moves []Move) error {
+	state := start
+
+	for idx, move := range moves {
+		if move.Before.Hash != state.Hash || board.GetZobristHash(move.Before) != board.GetZobristHash(state) {
+			return fmt.Errorf("move %d (%s) does not start from the state after the previous move", idx+1, move)
+		}
+
+		pieceIdx := state.getPieceIndex(move.Piece.Label)
+
+		if pieceIdx < 0 || move.Distance < 1 || move.Distance > 2 {
+			return fmt.Errorf("move %d (%s) is not a valid move", idx+1, move)
+		}
+
+		for step := 0; step < move.Distance; step++ {
+			piece := state.Pieces[pieceIdx]
+			startingBlock, _ := state.getPieceStartingBlock(piece)
+
+			if !state.canMove(piece, state.getMatrix(board.Width, board.Height), startingBlock, move.Direction) {
+				return fmt.Errorf("move %d (%s) is not legal", idx+1, move)
+			}
+
+			state = board.shiftPiece(state, pieceIdx, piece, move.Direction)
+		}
+
+		if err := board.validateState(move.After); err != nil {
+			return fmt.Errorf("move %d (%s): %s", idx+1, move, err)
+		}
+
+		if move.After.Hash != state.Hash || move.After.Hash != board.GetZobristHash(move.After) {
+			return fmt.Errorf("move %d (%s) leads to a state with an incorrect hash", idx+1, move)
+		}
+
+		if board.Print(move.After) != board.Print(state) {
+			return fmt.Errorf("move %d (%s) does not lead to the state after the move", idx+1, move)
+		}
+	}
+
+	return nil
+}
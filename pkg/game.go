@@ -0,0 +1,123 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrIllegalMove is returned by Game.Slide when a piece cannot be slid in a given direction.
+var ErrIllegalMove = errors.New("Illegal move")
+
+// Game is a puzzle played one slide at a time. Moves are counted the same way as by searches:
+// sliding the piece moved last again in the same direction, right after sliding it by one space,
+// extends that move instead of making a new one.
+type Game struct {
+	Board *Board
+	moves []Move
+	// Optimal solution found by the last hint, reused while the game follows it.
+	hint []Move
+}
+
+// NewGame returns a game starting from the initial state of a board.
+func NewGame(board *Board) *Game {
+	return &Game{Board: board}
+}
+
+// State returns the current state of the game.
+func (game *Game) State() State {
+	if len(game.moves) == 0 {
+		return game.Board.State
+	}
+
+	return game.moves[len(game.moves)-1].After
+}
+
+// Moves returns moves made so far.
+func (game *Game) Moves() []Move {
+	return game.moves
+}
+
+// IsSolved checks if the goal piece is in the goal position.
+func (game *Game) IsSolved() bool {
+	return game.Board.IsSolved(game.State())
+}
+
+// Slide slides a piece by one space in a given direction. Returns ErrIllegalMove if the piece cannot get there.
+func (game *Game) Slide(label string, direction Direction) error {
+	if last := len(game.moves) - 1; last >= 0 {
+		move := game.moves[last]
+
+		if move.Piece.Label == label && move.Direction == direction && move.Distance == 1 {
+			if extended, ok := game.findMove(move.Before, label, direction, 2); ok {
+				game.moves[last] = extended
+				return nil
+			}
+		}
+	}
+
+	move, ok := game.findMove(game.State(), label, direction, 1)
+	if !ok {
+		return fmt.Errorf("%w: piece %s cannot move %s", ErrIllegalMove, label, direction)
+	}
+
+	game.moves = append(game.moves, move)
+
+	return nil
+}
+
+// Undo takes back the last move. Returns false if there is none.
+func (game *Game) Undo() bool {
+	if len(game.moves) == 0 {
+		return false
+	}
+
+	game.moves = game.moves[:len(game.moves)-1]
+
+	return true
+}
+
+// Restart takes back all moves.
+func (game *Game) Restart() {
+	game.moves = nil
+}
+
+// Hint returns the first move of an optimal solution from the current state.
+// Returns ErrNoSolution if the game is solved already.
+func (game *Game) Hint(ctx context.Context, opts SolveOptions) (Move, error) {
+	state := game.State()
+
+	if game.Board.IsSolved(state) {
+		return Move{}, ErrNoSolution
+	}
+
+	for _, move := range game.hint {
+		if move.Before.Hash == state.Hash {
+			return move, nil
+		}
+	}
+
+	solution, err := game.Board.SolveFrom(ctx, state, opts)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if len(solution.Moves) == 0 {
+		return Move{}, ErrNoSolution
+	}
+
+	game.hint = solution.Moves
+
+	return solution.Moves[0], nil
+}
+
+// Returns a move of a piece by a given distance in a given direction from a state, if it is possible.
+func (game *Game) findMove(state State, label string, direction Direction, distance int) (Move, bool) {
+	for _, move := range game.Board.Moves(state) {
+		if move.Piece.Label == label && move.Direction == direction && move.Distance == distance {
+			return move, true
+		}
+	}
+
+	return Move{}, false
+}
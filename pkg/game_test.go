@@ -0,0 +1,78 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGameSlide(t *testing.T) {
+	board := initBoard()
+	game := NewGame(&board)
+	left, down, up := Direction{X: -1}, Direction{Y: 1}, Direction{Y: -1}
+
+	// Sliding the same piece again in the same direction extends the move.
+	if err := game.Slide("j", left); err != nil {
+		t.Fatalf("Cannot slide piece j left, got: %v", err)
+	}
+
+	if err := game.Slide("j", left); err != nil {
+		t.Fatalf("Cannot slide piece j left again, got: %v", err)
+	}
+
+	if len(game.Moves()) != 1 || game.Moves()[0].String() != "jLL" {
+		t.Errorf("Incorrect moves, got: %v, want: [jLL]", game.Moves())
+	}
+
+	if err := game.Slide("f", down); err != nil {
+		t.Fatalf("Cannot slide piece f down, got: %v", err)
+	}
+
+	if len(game.Moves()) != 2 || game.State().Hash != board.GetZobristHash(game.State()) {
+		t.Errorf("Incorrect moves, got: %v, want: [jLL fD]", game.Moves())
+	}
+
+	if err := game.Slide("a", up); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("Incorrect error of an illegal move, got: %v, want: %v", err, ErrIllegalMove)
+	}
+
+	if !game.Undo() || len(game.Moves()) != 1 || game.State().Hash != game.Moves()[0].After.Hash {
+		t.Errorf("Incorrect moves after undo, got: %v, want: [jLL]", game.Moves())
+	}
+
+	game.Restart()
+
+	if len(game.Moves()) != 0 || game.State().Hash != board.State.Hash || game.Undo() {
+		t.Errorf("Incorrect moves after restart, got: %v", game.Moves())
+	}
+}
+
+func TestGameHint(t *testing.T) {
+	board := initBoard()
+	game := NewGame(&board)
+
+	for step := 0; !game.IsSolved(); step++ {
+		if step > 90 {
+			t.Fatal("Puzzle not solved by following hints in 90 moves")
+		}
+
+		hint, err := game.Hint(context.Background(), SolveOptions{})
+		if err != nil {
+			t.Fatalf("Hint not found, got: %v", err)
+		}
+
+		for distance := 0; distance < hint.Distance; distance++ {
+			if err := game.Slide(hint.Piece.Label, hint.Direction); err != nil {
+				t.Fatalf("Cannot follow hint %s, got: %v", hint, err)
+			}
+		}
+	}
+
+	if len(game.Moves()) != 90 {
+		t.Errorf("Puzzle solved by following hints in incorrect number of moves, got: %d, want: 90", len(game.Moves()))
+	}
+
+	if _, err := game.Hint(context.Background(), SolveOptions{}); !errors.Is(err, ErrNoSolution) {
+		t.Errorf("Incorrect error of a hint for a solved game, got: %v, want: %v", err, ErrNoSolution)
+	}
+}
@@ -3,31 +3,74 @@ package klotski
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math"
 	"math/rand"
-	"sort"
 	"time"
 )
 
-// Board defines a board, stores an initial state and all states leading to the final state.
-// Also keep track of visited states.
+// Board defines a puzzle: size of the board, its goal and the initial state.
+// A board is not modified by searches, so it can be shared by any number of them, also concurrently.
 type Board struct {
-	Width               int
-	Height              int
-	ZobristHash         [][][]int
-	State               State
+	Width       int
+	Height      int
+	Goal        Goal
+	ZobristHash [][][]int
+	State       State
+}
+
+// Search holds a state of a single breadth-first search of a board: states found so far,
+// in the order they are expanded, and hashes of visited states.
+type Search struct {
+	Board               *Board
 	States              []State
 	VisitedStatesHashes map[int]bool
+
+	// Links of the search states to states they have been reached from, in the same order.
+	links []stateLink
+
+	// Number of states expanded and time spent by the search so far, kept to resume it.
+	expanded int
+	elapsed  time.Duration
+
+	// Buffers reused to expand the search states, created on first use.
+	generator *moveGenerator
 }
 
-// State defines a state of the board for each move, has reference to a parent state (before the move).
+// Generates moves from states of a board, reusing its buffers for all of them. Not safe for concurrent use.
+type moveGenerator struct {
+	board *Board
+	// Index of the piece covering each space of the board plus one, 0 for empty spaces, row by row.
+	grid []int
+	// Starting block (top left one) of each piece of the current state.
+	anchors []Block
+}
+
+// State defines positions of all pieces on the board.
 type State struct {
-	Pieces        []Piece
-	Hash          int
-	Parent        *State
-	Step          int
-	MoveDirection string
-	MovePiece     Piece
+	Pieces []Piece
+	Hash   int
+}
+
+// Move is a single step of a solution: a piece slid by one or more spaces in the same direction.
+type Move struct {
+	// Piece in its position before the move.
+	Piece     Piece
+	Direction Direction
+	Distance  int
+	Before    State
+	After     State
+}
+
+// Link of a board state to the state it has been reached from by a single move.
+type stateLink struct {
+	// Index of the board state the move starts from, -1 for the initial state.
+	parent    int
+	pieceIdx  int
+	direction Direction
+	distance  int
+	// Number of moves leading to the state.
+	depth int
 }
 
 // Piece holds informatation about a piece on a board. Each piece is a combination of one or more single blocks.
@@ -43,25 +86,32 @@ type Block struct {
 	X, Y int
 }
 
-// Move defines a movement of a piece / block.
-type Move struct {
-	X, Y int
+// Goal defines where a piece has to be moved (its top left block) to solve the puzzle.
+type Goal struct {
+	Label string
+	X, Y  int
 }
 
-// Returns a list of possible moves.
-func getMoves() []Move {
-	moves := make([]Move, 4, 4)
+// Direction defines a movement of a piece / block by one space.
+type Direction struct {
+	X, Y int
+}
 
-	moves[0] = Move{0, 1}  // DOWN
-	moves[1] = Move{1, 0}  // RIGHT
-	moves[2] = Move{0, -1} // UP
-	moves[3] = Move{-1, 0} // LEFT
+// Possible directions, shared so listing them does not allocate.
+var directions = [4]Direction{
+	{0, 1},  // DOWN
+	{1, 0},  // RIGHT
+	{0, -1}, // UP
+	{-1, 0}, // LEFT
+}
 
-	return moves
+// Returns a list of possible directions. It must not be modified.
+func getDirections() []Direction {
+	return directions[:]
 }
 
-// Returns string represenation of a move.
-func (m *Move) getString() string {
+// Returns string represenation of a direction.
+func (m *Direction) getString() string {
 	var moveString string
 
 	if m.X == 0 && m.Y > 0 {
@@ -77,26 +127,101 @@ func (m *Move) getString() string {
 	return moveString
 }
 
+// String returns a name of a direction, i.e. "down".
+func (m Direction) String() string {
+	return m.getString()
+}
+
+// NewBoard returns a board ready to be solved for a given initial state.
+func NewBoard(width, height int, state State, goal Goal) Board {
+	board := Board{
+		Width:  width,
+		Height: height,
+		Goal:   goal,
+		State:  state,
+	}
+
+	board.ZobristHash = board.InitZorbistHash()
+	board.State.Hash = board.GetZobristHash(board.State)
+
+	return board
+}
+
+// NewSearch returns a search of the board starting from a given state, i.e. a state reached by some moves
+// from the initial state. Returns an error if the state does not have the same pieces as the initial state
+// or they do not fit on the board.
+func (board *Board) NewSearch(state State) (*Search, error) {
+	if err := board.validateState(state); err != nil {
+		return nil, err
+	}
+
+	state.Hash = board.GetZobristHash(state)
+
+	search := &Search{
+		Board:               board,
+		States:              []State{state},
+		VisitedStatesHashes: make(map[int]bool, 0),
+		links:               []stateLink{{parent: -1}},
+	}
+
+	return search, nil
+}
+
+// Checks that a state has the same pieces as the initial state, within the board and not overlapping each other.
+func (board *Board) validateState(state State) error {
+	if len(state.Pieces) != len(board.State.Pieces) {
+		return fmt.Errorf("Invalid state: %d pieces, want: %d", len(state.Pieces), len(board.State.Pieces))
+	}
+
+	occupied := make(map[Block]bool)
+
+	for idx, piece := range state.Pieces {
+		initial := board.State.Pieces[idx]
+
+		if piece.Label != initial.Label || piece.Width != initial.Width || piece.Height != initial.Height ||
+			len(piece.Blocks) != len(initial.Blocks) {
+			return fmt.Errorf("Invalid state: piece %s does not match piece %s of the initial state", piece.Label, initial.Label)
+		}
+
+		for _, block := range piece.Blocks {
+			if block.X < 0 || block.Y < 0 || block.X >= board.Width || block.Y >= board.Height {
+				return fmt.Errorf("Invalid state: piece %s is outside of the board", piece.Label)
+			}
+
+			if occupied[block] {
+				return fmt.Errorf("Invalid state: piece %s overlaps another piece", piece.Label)
+			}
+
+			occupied[block] = true
+		}
+	}
+
+	return nil
+}
+
 // InitZorbistHash initialises Zorbist hash for the board.
 // Reference: https://en.wikipedia.org/wiki/Zobrist_hashing
 func (board *Board) InitZorbistHash() [][][]int {
-	const rows int = 5
-	const cols int = 4
+	rows, cols := board.Height, board.Width
+	pieceTypes := board.getNumberOfPieceTypes()
+
+	// Hashes of all states are kept instead of the states, so collisions have to be unlikely
+	// even for millions of states.
+	random := rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
 
 	zobristTable := make([][][]int, rows)
 	for row := 0; row < rows; row++ {
 		zobristTable[row] = make([][]int, cols)
 
 		for col := 0; col < cols; col++ {
-			zobristTable[row][col] = make([]int, 5)
+			zobristTable[row][col] = make([]int, pieceTypes)
 		}
 	}
 
 	for row := 0; row < rows; row++ {
 		for col := 0; col < cols; col++ {
-			for idx := 0; idx < 5; idx++ {
-				rand.Seed(time.Now().UTC().UnixNano())
-				zobristTable[row][col][idx] = int(rand.Float64() * math.Pow(2.0, 31.0))
+			for idx := 0; idx < pieceTypes; idx++ {
+				zobristTable[row][col][idx] = int(random.Int63())
 			}
 		}
 	}
@@ -104,25 +229,38 @@ func (board *Board) InitZorbistHash() [][][]int {
 	return zobristTable
 }
 
-// GetZobristHash returns hash of a state.
-func (board *Board) GetZobristHash(state State) int {
-	const rows int = 5
-	const cols int = 4
+// Returns number of piece types used by Zorbist hash: an empty space, each possible size of a piece
+// and the goal piece, which must not be confused with other pieces of the same size.
+func (board *Board) getNumberOfPieceTypes() int {
+	return board.Width*board.Height + 2
+}
 
-	hash := 0
+// Returns type of a piece used by Zorbist hash. Pieces of the same size are interchangeable.
+func (board *Board) getPieceType(piece Piece) int {
+	if piece.Label == board.Goal.Label {
+		return board.Width*board.Height + 1
+	}
 
-	var pieceTypes [2][2]int
+	return (piece.Width-1)*board.Height + piece.Height
+}
 
-	pieceTypes[0][1] = 1
-	pieceTypes[1][1] = 2
-	pieceTypes[1][0] = 3
-	pieceTypes[0][0] = 4
+// GetZobristHash returns hash of a state, including empty spaces, so it is equal to hashes updated by moves.
+func (board *Board) GetZobristHash(state State) int {
+	hash := 0
 
 	for _, piece := range state.Pieces {
+		pieceType := board.getPieceType(piece)
+
 		for _, block := range piece.Blocks {
-			x, y := block.X, block.Y
-			pieceType := pieceTypes[piece.Width-1][piece.Height-1]
-			hash ^= board.ZobristHash[y][x][pieceType]
+			hash ^= board.ZobristHash[block.Y][block.X][pieceType]
+			hash ^= board.ZobristHash[block.Y][block.X][0]
+		}
+	}
+
+	// Starts from all spaces being empty, the empty space of each block been removed above.
+	for row := 0; row < board.Height; row++ {
+		for col := 0; col < board.Width; col++ {
+			hash ^= board.ZobristHash[row][col][0]
 		}
 	}
 
@@ -130,185 +268,253 @@ func (board *Board) GetZobristHash(state State) int {
 }
 
 // Returns updated Zorbist hash for a moved piece.
-func (board *Board) getUpdatedZobristHash(state State, piece Piece, move Move) int {
-	hash := state.Hash
-
-	var pieceTypes [2][2]int
+func (board *Board) getUpdatedZobristHash(state State, piece Piece, move Direction) int {
+	return board.getMovedZobristHash(state.Hash, piece, move, 1)
+}
 
-	pieceTypes[0][1] = 1
-	pieceTypes[1][1] = 2
-	pieceTypes[1][0] = 3
-	pieceTypes[0][0] = 4
+// Returns Zorbist hash updated for a piece moved by a given number of spaces in the same direction.
+func (board *Board) getMovedZobristHash(hash int, piece Piece, move Direction, distance int) int {
+	pieceType := board.getPieceType(piece)
 
-	pieceType := pieceTypes[piece.Width-1][piece.Height-1]
+	for step := 0; step < distance; step++ {
+		for _, block := range piece.Blocks {
+			x, y := block.X+move.X*step, block.Y+move.Y*step
 
-	for _, block := range piece.Blocks {
-		hash ^= board.ZobristHash[block.Y][block.X][pieceType]
-		hash ^= board.ZobristHash[block.Y][block.X][0]
-		hash ^= board.ZobristHash[block.Y+move.Y][block.X+move.X][0]
-		hash ^= board.ZobristHash[block.Y+move.Y][block.X+move.X][pieceType]
+			hash ^= board.ZobristHash[y][x][pieceType]
+			hash ^= board.ZobristHash[y][x][0]
+			hash ^= board.ZobristHash[y+move.Y][x+move.X][0]
+			hash ^= board.ZobristHash[y+move.Y][x+move.X][pieceType]
+		}
 	}
 
 	return hash
 }
 
-// Moves a piece in a given direction.
-// Returns a new state or error if state been visited already.
-func (board *Board) movePiece(state State, pieceIdx int, piece Piece, move Move) (State, error) {
-
-	hash := board.getUpdatedZobristHash(state, piece, move)
+// Returns a new state with a piece shifted by one space in a given direction, without any checks.
+func (board *Board) shiftPiece(state State, pieceIdx int, piece Piece, move Direction) State {
+	return board.movePiece(state, pieceIdx, piece, move, 1)
+}
 
-	_, visited := board.VisitedStatesHashes[hash]
+// Returns a new state with a piece moved by a given number of spaces in the same direction, without any checks.
+// Allocates only the pieces of the new state and blocks of the moved piece.
+func (board *Board) movePiece(state State, pieceIdx int, piece Piece, move Direction, distance int) State {
+	movedBlocks := make([]Block, len(piece.Blocks))
 
-	if visited {
-		return State{}, errors.New("State visited already")
+	for idx, block := range piece.Blocks {
+		movedBlocks[idx] = Block{X: block.X + move.X*distance, Y: block.Y + move.Y*distance}
 	}
 
-	var movedBlocks []Block
-	for _, block := range piece.Blocks {
-		newBlock := Block{X: block.X + move.X, Y: block.Y + move.Y}
-		movedBlocks = append(movedBlocks, newBlock)
-	}
+	newPieces := make([]Piece, len(state.Pieces))
+
+	copy(newPieces, state.Pieces)
 
-	newPiece := Piece{
+	newPieces[pieceIdx] = Piece{
 		Label:  piece.Label,
 		Width:  piece.Width,
 		Height: piece.Height,
 		Blocks: movedBlocks,
 	}
 
-	newPieces := make([]Piece, len(state.Pieces))
+	return State{
+		Pieces: newPieces,
+		Hash:   board.getMovedZobristHash(state.Hash, piece, move, distance),
+	}
+}
 
-	copy(newPieces, state.Pieces)
+// Calls a function for each state reachable in one move, which is moving a piece by one or two spaces
+// in the same direction, regardless of states being visited already.
+func (board *Board) forEachNeighbour(state State, fn func(newState State, pieceIdx int, move Direction, distance int)) {
+	board.newMoveGenerator().forEachNeighbour(state, fn)
+}
+
+// Moves returns all moves possible from a given state, counted the same way as by searches:
+// a piece slid by one or two spaces in the same direction.
+func (board *Board) Moves(state State) []Move {
+	moves := make([]Move, 0)
+
+	board.forEachNeighbour(state, func(newState State, pieceIdx int, move Direction, distance int) {
+		moves = append(moves, Move{
+			Piece:     state.Pieces[pieceIdx],
+			Direction: move,
+			Distance:  distance,
+			Before:    state,
+			After:     newState,
+		})
+	})
 
-	newPieces[pieceIdx] = newPiece
+	return moves
+}
 
-	newState := State{
-		Pieces:        newPieces,
-		Parent:        &state,
-		Step:          state.Step + 1,
-		MovePiece:     newPiece,
-		MoveDirection: move.getString(),
-		Hash:          state.Hash,
+// Returns a move generator for states of a board.
+func (board *Board) newMoveGenerator() *moveGenerator {
+	return &moveGenerator{
+		board: board,
+		grid:  make([]int, board.Width*board.Height),
 	}
+}
+
+// Calls a function for each state reachable in one move, like Board.forEachNeighbour, but without allocating
+// anything other than the new states.
+func (generator *moveGenerator) forEachNeighbour(state State, fn func(newState State, pieceIdx int, move Direction, distance int)) {
+	generator.load(state)
 
-	newState.Hash = hash
+	for pieceIdx, piece := range state.Pieces {
+		anchor := generator.anchors[pieceIdx]
 
-	return newState, nil
+		for _, move := range directions {
+			for distance := 1; distance <= 2 && generator.isFree(piece, anchor, move, distance); distance++ {
+				fn(generator.board.movePiece(state, pieceIdx, piece, move, distance), pieceIdx, move, distance)
+			}
+		}
+	}
 }
 
-// Finds new states for all possible (and not visited) moves and adds them the board states.
-func (board *Board) findNewStates(state State) {
+// Fills the occupancy grid and starting blocks of pieces for a state.
+func (generator *moveGenerator) load(state State) {
+	for idx := range generator.grid {
+		generator.grid[idx] = 0
+	}
 
-	stateMatrix := state.getMatrix()
+	generator.anchors = generator.anchors[:0]
 
 	for pieceIdx, piece := range state.Pieces {
+		anchor := Block{X: math.MaxInt32, Y: math.MaxInt32}
+
+		for _, block := range piece.Blocks {
+			generator.grid[block.Y*generator.board.Width+block.X] = pieceIdx + 1
 
-		startingBlock, _ := state.getPieceStartingBlock(piece)
-
-		for _, move := range getMoves() {
-			canMove := state.canMove(piece, stateMatrix, startingBlock, move)
-
-			if canMove {
-				newState, err := board.movePiece(state, pieceIdx, piece, move)
-				if err == nil {
-					_, visited := board.VisitedStatesHashes[newState.Hash]
-					if visited == false {
-						board.VisitedStatesHashes[newState.Hash] = true
-						board.States = append(board.States, newState)
-
-						newStateMatrix := newState.getMatrix()
-						startingBlockOnNewState, _ := newState.getPieceStartingBlock(piece)
-						pieceInNewState := newState.Pieces[pieceIdx]
-						canMoveAgain := newState.canMove(pieceInNewState, newStateMatrix, startingBlockOnNewState, move)
-
-						if canMoveAgain {
-							newState2, err2 := board.movePiece(newState, pieceIdx, pieceInNewState, move)
-
-							if err2 == nil {
-								_, visited := board.VisitedStatesHashes[newState2.Hash]
-
-								if visited == false {
-									board.VisitedStatesHashes[newState2.Hash] = true
-									newState2.Step--
-									board.States = append(board.States, newState2)
-								}
-							}
-						}
-					}
-				}
+			if block.X < anchor.X {
+				anchor.X = block.X
+			}
+
+			if block.Y < anchor.Y {
+				anchor.Y = block.Y
 			}
 		}
+
+		generator.anchors = append(generator.anchors, anchor)
 	}
 }
 
-// Solve finds a solution for the initial board state
-func (board *Board) Solve() ([]State, error) {
-
-	results := make([]State, 0)
+// Checks if spaces entered by a piece on its last step of a move by a given distance are on the board and empty.
+// Spaces entered on the previous steps have to be checked separately.
+func (generator *moveGenerator) isFree(piece Piece, anchor Block, move Direction, distance int) bool {
+	x, y, width, height := anchor.X, anchor.Y, piece.Width, piece.Height
+
+	switch {
+	case move.Y > 0:
+		y, height = y+piece.Height-1+distance, 1
+	case move.Y < 0:
+		y, height = y-distance, 1
+	case move.X > 0:
+		x, width = x+piece.Width-1+distance, 1
+	case move.X < 0:
+		x, width = x-distance, 1
+	}
 
-	for idx := 0; idx < len(board.States); idx++ {
+	if x < 0 || y < 0 || x+width > generator.board.Width || y+height > generator.board.Height {
+		return false
+	}
 
-		currentState := board.States[idx]
+	for row := y; row < y+height; row++ {
+		for col := x; col < x+width; col++ {
+			if generator.grid[row*generator.board.Width+col] != 0 {
+				return false
+			}
+		}
+	}
 
-		board.VisitedStatesHashes[currentState.Hash] = true
+	return true
+}
 
-		if currentState.isFinal() {
+// Finds new states for all possible (and not visited) moves from a search state and adds them to the search states.
+func (search *Search) findNewStates(stateIdx int) {
+	state := search.States[stateIdx]
+	depth := search.links[stateIdx].depth
 
-			end := false
-			newState := currentState
+	if search.generator == nil {
+		search.generator = search.Board.newMoveGenerator()
+	}
 
-			uniqueStates := make(map[int]State, 0)
+	search.generator.forEachNeighbour(state, func(newState State, pieceIdx int, move Direction, distance int) {
+		if search.VisitedStatesHashes[newState.Hash] {
+			return
+		}
 
-			idx2 := 1
+		search.VisitedStatesHashes[newState.Hash] = true
+		search.States = append(search.States, newState)
+		search.links = append(search.links, stateLink{
+			parent:    stateIdx,
+			pieceIdx:  pieceIdx,
+			direction: move,
+			distance:  distance,
+			depth:     depth + 1,
+		})
+	})
+}
 
-			for end == false {
+// Returns moves leading from the starting state to a given search state, following links of the search states.
+func (search *Search) getSolution(stateIdx int) []Move {
+	moves := make([]Move, search.links[stateIdx].depth)
 
-				if newState.Parent != nil || newState.Step > 0 {
-					uniqueStates[newState.Step] = newState
+	for idx := stateIdx; search.links[idx].parent >= 0; idx = search.links[idx].parent {
+		link := search.links[idx]
+		before := search.States[link.parent]
 
-					idx2++
+		moves[link.depth-1] = Move{
+			Piece:     before.Pieces[link.pieceIdx],
+			Direction: link.direction,
+			Distance:  link.distance,
+			Before:    before,
+			After:     search.States[idx],
+		}
+	}
 
-					if newState.MovePiece.Label == newState.Parent.MovePiece.Label && newState.MoveDirection == newState.Parent.MoveDirection {
-						newState = *newState.Parent
-					}
+	return moves
+}
 
-					newState = *newState.Parent
-				} else {
-					end = true
-				}
-			}
+// Returns a move of the piece which starting block is at one position to another one in a straight line,
+// or an error if there is no such piece or it cannot get there.
+func (board *Board) getMove(state State, from, to Block) (Move, error) {
+	pieceIdx := -1
 
-			var steps []int
+	for idx, piece := range state.Pieces {
+		if startingBlock, _ := state.getPieceStartingBlock(piece); startingBlock == from {
+			pieceIdx = idx
+		}
+	}
 
-			for step := range uniqueStates {
-				steps = append(steps, step)
-			}
+	if pieceIdx < 0 || from == to || (from.X != to.X && from.Y != to.Y) {
+		return Move{}, fmt.Errorf("Cannot move a piece from %d,%d to %d,%d", from.X, from.Y, to.X, to.Y)
+	}
 
-			sort.Ints(steps)
+	move := Move{
+		Piece:     state.Pieces[pieceIdx],
+		Direction: Direction{X: sign(to.X - from.X), Y: sign(to.Y - from.Y)},
+		Before:    state,
+		After:     state,
+	}
 
-			for _, step := range steps {
-				results = append(results, uniqueStates[step])
-			}
+	for block := from; block != to; block = (Block{X: block.X + move.Direction.X, Y: block.Y + move.Direction.Y}) {
+		piece := move.After.Pieces[pieceIdx]
 
-			return results, nil
+		if !move.After.canMove(piece, move.After.getMatrix(board.Width, board.Height), block, move.Direction) {
+			return Move{}, fmt.Errorf("Cannot move piece %s from %d,%d to %d,%d", piece.Label, from.X, from.Y, to.X, to.Y)
 		}
 
-		board.findNewStates(currentState)
+		move.After = board.shiftPiece(move.After, pieceIdx, piece, move.Direction)
+		move.Distance++
 	}
 
-	return results, errors.New("Cannot solve")
+	return move, nil
 }
 
 // Returns starting block (top left one) of a piece.
 func (state *State) getPieceStartingBlock(piece Piece) (Block, error) {
-	const rows int = 5
-	const cols int = 4
-
 	var startingBlock Block
 
 	for _, p := range state.Pieces {
-		x, y := cols, rows
+		x, y := math.MaxInt32, math.MaxInt32
 		for _, b := range p.Blocks {
 			if b.X < x {
 				x = b.X
@@ -331,10 +537,7 @@ func (state *State) getPieceStartingBlock(piece Piece) (Block, error) {
 }
 
 // Returns a matrix for a given state.
-func (state *State) getMatrix() [][]string {
-	const rows int = 5
-	const cols int = 4
-
+func (state *State) getMatrix(cols, rows int) [][]string {
 	boardMatrix := make([][]string, rows)
 
 	for row := 0; row < rows; row++ {
@@ -357,9 +560,8 @@ func (state *State) getMatrix() [][]string {
 }
 
 // Checks if a piece can be moved in a given direction.
-func (state *State) canMove(piece Piece, boardMatrix [][]string, startingBlock Block, move Move) bool {
-	const rows int = 5
-	const cols int = 4
+func (state *State) canMove(piece Piece, boardMatrix [][]string, startingBlock Block, move Direction) bool {
+	rows, cols := len(boardMatrix), len(boardMatrix[0])
 
 	canMove := false
 
@@ -405,13 +607,18 @@ func (state *State) canMove(piece Piece, boardMatrix [][]string, startingBlock B
 	return canMove
 }
 
-// Checks if a state is a final one.
-func (state *State) isFinal() bool {
+// IsSolved checks if the goal piece is in the goal position in a given state.
+func (board *Board) IsSolved(state State) bool {
+	return state.isFinal(board.Goal)
+}
+
+// Checks if a state is a final one, i.e. the goal piece is in the goal position.
+func (state *State) isFinal(goal Goal) bool {
 	for _, piece := range state.Pieces {
-		if piece.Label == "b" {
+		if piece.Label == goal.Label {
 			startingBlock, _ := state.getPieceStartingBlock(piece)
 
-			if startingBlock.Y == 3 && startingBlock.X == 1 {
+			if startingBlock.Y == goal.Y && startingBlock.X == goal.X {
 				return true
 			}
 		}
@@ -422,47 +629,66 @@ func (state *State) isFinal() bool {
 
 // Print a given board state
 func (board *Board) Print(state State) string {
-	const rows int = 5
-	const cols int = 4
-
 	var buffer bytes.Buffer
 
-	var stateMatrix [rows][cols]string
+	stateMatrix := state.getMatrix(board.Width, board.Height)
 
-	for row := 0; row < rows; row++ {
-		for col := 0; col < cols; col++ {
-			stateMatrix[row][col] = "_"
-		}
-	}
-
-	for _, piece := range state.Pieces {
-		for _, block := range piece.Blocks {
-			stateMatrix[block.Y][block.X] = piece.Label
-		}
-	}
-
-	for rowIdx := 0; rowIdx < board.Width+2; rowIdx++ {
-		buffer.WriteString("X ")
+	for colIdx := -1; colIdx <= board.Width; colIdx++ {
+		buffer.WriteString(board.getBorder(colIdx, -1))
 	}
 
 	buffer.WriteString("\n")
 
 	for rowIdx := 0; rowIdx < board.Height; rowIdx++ {
-		buffer.WriteString("X ")
+		buffer.WriteString(board.getBorder(-1, rowIdx))
 		for colIdx := 0; colIdx < board.Width; colIdx++ {
 			buffer.WriteString(stateMatrix[rowIdx][colIdx] + " ")
 		}
-		buffer.WriteString("X \n")
+		buffer.WriteString(board.getBorder(board.Width, rowIdx) + "\n")
 	}
-	for rowIdx := 0; rowIdx < board.Width+2; rowIdx++ {
-		if rowIdx == 2 || rowIdx == 3 {
-			buffer.WriteString("Z ")
-		} else {
-			buffer.WriteString("X ")
-		}
+	for colIdx := -1; colIdx <= board.Width; colIdx++ {
+		buffer.WriteString(board.getBorder(colIdx, board.Height))
 	}
 
 	buffer.WriteString("\n")
 
 	return buffer.String()
 }
+
+// Returns a border of the board at given coordinates, "Z" marks the exit next to the goal position.
+func (board *Board) getBorder(x, y int) string {
+	if board.IsExit(x, y) {
+		return "Z "
+	}
+
+	return "X "
+}
+
+// IsExit checks if given coordinates outside of the board, i.e. -1 or the width, are next to the goal position
+// of the goal piece.
+func (board *Board) IsExit(x, y int) bool {
+	var goalPiece Piece
+
+	for _, piece := range board.State.Pieces {
+		if piece.Label == board.Goal.Label {
+			goalPiece = piece
+		}
+	}
+
+	goal := board.Goal
+	insideX := x >= goal.X && x < goal.X+goalPiece.Width
+	insideY := y >= goal.Y && y < goal.Y+goalPiece.Height
+
+	switch {
+	case y == -1 && goal.Y == 0:
+		return insideX
+	case y == board.Height && goal.Y+goalPiece.Height == board.Height:
+		return insideX
+	case x == -1 && goal.X == 0:
+		return insideY
+	case x == board.Width && goal.X+goalPiece.Width == board.Width:
+		return insideY
+	}
+
+	return false
+}
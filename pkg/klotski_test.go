@@ -1,11 +1,12 @@
 package klotski
 
 import (
+	"context"
 	"testing"
 )
 
 func TestMoves(t *testing.T) {
-	moves := getMoves()
+	moves := getDirections()
 
 	expectedMoves := 4
 
@@ -15,7 +16,7 @@ func TestMoves(t *testing.T) {
 }
 
 func TestMoveGetString(t *testing.T) {
-	moves := getMoves()
+	moves := getDirections()
 
 	expectedStrings := make([]string, 4)
 	expectedStrings[0] = "down"
@@ -35,42 +36,147 @@ func TestMoveGetString(t *testing.T) {
 	}
 }
 
-func TestMovePiece(t *testing.T) {
+func TestInitZorbistHash(t *testing.T) {
 	board := initBoard()
-	state := board.States[0]
-	pieceIdx := 0
-	piece := state.Pieces[pieceIdx]
-	move := getMoves()[0]
+	seen := make(map[int]bool)
+	wide := false
+
+	for _, row := range board.InitZorbistHash() {
+		for _, types := range row {
+			for _, value := range types {
+				if seen[value] {
+					t.Fatalf("Zobrist hash has a repeated value, got: %d", value)
+				}
+
+				seen[value] = true
+				wide = wide || value >= 1<<31
+			}
+		}
+	}
+
+	// Values are drawn from a single source instead of reseeding it with the time for each of them,
+	// and span 63 bits, so hashes of millions of states kept by searches are unlikely to collide.
+	if !wide {
+		t.Error("Zobrist hash has only 31 bit values.")
+	}
+}
 
-	newState, _ := board.movePiece(state, pieceIdx, piece, move)
-	board.VisitedStatesHashes[newState.Hash] = true
+func TestFindNewStatesVisited(t *testing.T) {
+	board := initBoard()
+	search, _ := board.NewSearch(board.State)
+	state := search.States[0]
+	pieceIdx := state.getPieceIndex("g")
+	move := getDirections()[0]
+
+	newState := board.shiftPiece(state, pieceIdx, state.Pieces[pieceIdx], move)
+	search.VisitedStatesHashes[newState.Hash] = true
+
+	search.findNewStates(0)
+
+	// Initial state + 6 moves found by TestFindNewStates without the visited one
+	expectedNumberOfStates := 6
+
+	if len(search.States) != expectedNumberOfStates {
+		t.Errorf("Search has incorrect number of states in the queue, got: %d, want: %d", len(search.States), expectedNumberOfStates)
+	}
+}
 
-	_, err2 := board.movePiece(state, pieceIdx, piece, move)
+func TestFindNewStatesTwoSpaces(t *testing.T) {
+	board := initBoard()
+	search, _ := board.NewSearch(board.State)
+	state := search.States[0]
+	pieceIdx := state.getPieceIndex("i")
+	right := getDirections()[1]
+
+	// The state after the first space of the move has been reached by another move already.
+	oneSpace := board.movePiece(state, pieceIdx, state.Pieces[pieceIdx], right, 1)
+	twoSpaces := board.movePiece(state, pieceIdx, state.Pieces[pieceIdx], right, 2)
+	search.VisitedStatesHashes[oneSpace.Hash] = true
+
+	search.findNewStates(0)
+
+	for idx, newState := range search.States[1:] {
+		if newState.Hash == twoSpaces.Hash {
+			if link := search.links[idx+1]; link.distance != 2 || link.depth != 1 {
+				t.Errorf("Incorrect link of the move by two spaces, got: %+v", link)
+			}
 
-	if err2 == nil {
-		t.Errorf("Error not returned, got %v, want: %s", err2, "State visited already")
+			return
+		}
 	}
+
+	t.Error("Move by two spaces not found when the state after the first space has been visited.")
 }
 
 func TestFindNewStates(t *testing.T) {
 	board := initBoard()
-	state := board.States[0]
+	search, _ := board.NewSearch(board.State)
 
 	expectedNumberOfStates := 1
 
-	if len(board.States) > expectedNumberOfStates {
-		t.Errorf("Board has incorrect number of states in the queue, got: %d, want: %d", len(board.States), expectedNumberOfStates)
+	if len(search.States) > expectedNumberOfStates {
+		t.Errorf("Search has incorrect number of states in the queue, got: %d, want: %d", len(search.States), expectedNumberOfStates)
 	}
 
-	board.findNewStates(state)
+	search.findNewStates(0)
 
 	// Initial 1 state + 4 single moves and 2 additional moves in the same direction
 	expectedNumberOfStates = 7
 
-	if len(board.States) != expectedNumberOfStates {
-		t.Errorf("Board has incorrect number of states in the queue, got: %d, want: %d", len(board.States), expectedNumberOfStates)
+	if len(search.States) != expectedNumberOfStates {
+		t.Errorf("Search has incorrect number of states in the queue, got: %d, want: %d", len(search.States), expectedNumberOfStates)
+	}
+
+	for idx, link := range search.links[1:] {
+		if link.parent != 0 || link.depth != 1 || link.distance < 1 || link.distance > 2 {
+			t.Errorf("Incorrect link of state %d, got: %+v", idx+1, link)
+		}
+	}
+}
+
+func TestFindNewStatesAllocations(t *testing.T) {
+	board := initBoard()
+	search, _ := board.NewSearch(board.State)
+
+	search.findNewStates(0)
+	newStates := len(search.States) - 1
+
+	allocs := testing.AllocsPerRun(100, func() {
+		search.States, search.links = search.States[:1], search.links[:1]
+
+		for hash := range search.VisitedStatesHashes {
+			delete(search.VisitedStatesHashes, hash)
+		}
+
+		search.findNewStates(0)
+	})
+
+	// Pieces of each new state and blocks of its moved piece.
+	if want := float64(2 * newStates); allocs > want {
+		t.Errorf("Expanding a state allocates too much, got: %v, want: at most %v", allocs, want)
+	}
+}
+
+func TestBoardMoves(t *testing.T) {
+	board := initBoard()
+	moves := board.Moves(board.State)
+
+	// 4 single moves and 2 additional moves in the same direction
+	expectedNumberOfMoves := 6
+
+	if len(moves) != expectedNumberOfMoves {
+		t.Errorf("Incorrect number of moves, got: %d, want: %d", len(moves), expectedNumberOfMoves)
 	}
 
+	for _, move := range moves {
+		if move.After.Hash != board.GetZobristHash(move.After) || move.Before.Hash != board.State.Hash {
+			t.Errorf("Move %s has incorrect states", move)
+		}
+	}
+
+	if board.IsSolved(board.State) {
+		t.Errorf("Initial state is solved")
+	}
 }
 
 func TestSolve(t *testing.T) {
@@ -83,9 +189,62 @@ func TestSolve(t *testing.T) {
 	}
 }
 
+func TestSolveMoves(t *testing.T) {
+	board := initBoard()
+
+	moves, err := board.Solve()
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	before := board.State
+
+	for idx, move := range moves {
+		if move.Before.Hash != before.Hash {
+			t.Errorf("Move %d does not start from the state after the previous move", idx+1)
+		}
+
+		pieceIdx := before.getPieceIndex(move.Piece.Label)
+		after := before
+
+		for step := 0; step < move.Distance; step++ {
+			after = board.shiftPiece(after, pieceIdx, after.Pieces[pieceIdx], move.Direction)
+		}
+
+		if move.Distance < 1 || move.Distance > 2 || move.After.Hash != after.Hash {
+			t.Errorf("Move %d (%s) does not lead to the state after the move", idx+1, move)
+		}
+
+		before = move.After
+	}
+
+	if !before.isFinal(board.Goal) {
+		t.Error("Solution does not reach the final state.")
+	}
+}
+
+func TestGetMove(t *testing.T) {
+	board := initBoard()
+
+	move, err := board.getMove(board.State, Block{X: 3, Y: 4}, Block{X: 1, Y: 4})
+
+	if err != nil {
+		t.Fatalf("Move not found, got: %v", err)
+	}
+
+	if move.String() != "jLL" || move.Piece.Blocks[0] != (Block{X: 3, Y: 4}) || move.After.Pieces[9].Blocks[0] != (Block{X: 1, Y: 4}) {
+		t.Errorf("Incorrect move, got: %s from %+v to %+v, want: jLL", move, move.Piece.Blocks, move.After.Pieces[9].Blocks)
+	}
+
+	if _, err := board.getMove(board.State, Block{X: 3, Y: 4}, Block{X: 3, Y: 2}); err == nil {
+		t.Error("Error not returned for a blocked move.")
+	}
+}
+
 func TestGetPieceStartingBlock(t *testing.T) {
 	board := initBoard()
-	state := board.States[0]
+	state := board.State
 	piece := state.Pieces[1]
 
 	startingBlock, _ := state.getPieceStartingBlock(piece)
@@ -98,9 +257,9 @@ func TestGetPieceStartingBlock(t *testing.T) {
 
 func TestGetMatrix(t *testing.T) {
 	board := initBoard()
-	state := board.States[0]
+	state := board.State
 
-	stateMatrix := state.getMatrix()
+	stateMatrix := state.getMatrix(board.Width, board.Height)
 
 	expectedRows := 5
 	expectedCols := 4
@@ -132,12 +291,12 @@ func TestGetMatrix(t *testing.T) {
 func TestCanMove(t *testing.T) {
 	board := initBoard()
 
-	state := board.States[0]
-	stateMatrix := state.getMatrix()
+	state := board.State
+	stateMatrix := state.getMatrix(board.Width, board.Height)
 	pieceIdx := 0
 	piece := state.Pieces[pieceIdx]
 	startingBlock, _ := state.getPieceStartingBlock(piece)
-	move := getMoves()[0]
+	move := getDirections()[0]
 
 	canMove := state.canMove(piece, stateMatrix, startingBlock, move)
 
@@ -148,7 +307,7 @@ func TestCanMove(t *testing.T) {
 	pieceIdx = 9
 	piece = state.Pieces[pieceIdx]
 	startingBlock, _ = state.getPieceStartingBlock(piece)
-	move = getMoves()[3]
+	move = getDirections()[3]
 
 	canMove = state.canMove(piece, stateMatrix, startingBlock, move)
 
@@ -159,18 +318,107 @@ func TestCanMove(t *testing.T) {
 
 func TestIsFinal(t *testing.T) {
 	board := finalBoard()
-	state := board.States[0]
+	state := board.State
 
-	if state.isFinal() == true {
+	if state.isFinal(board.Goal) == true {
 		t.Error("State is not final.")
 	}
 }
 
+func BenchmarkGetZobristHash(b *testing.B) {
+	board := initBoard()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		board.GetZobristHash(board.State)
+	}
+}
+
+func BenchmarkGetUpdatedZobristHash(b *testing.B) {
+	board := initBoard()
+	piece := board.State.Pieces[board.State.getPieceIndex("j")]
+	move := Direction{X: -1, Y: 0}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		board.getUpdatedZobristHash(board.State, piece, move)
+	}
+}
+
+func BenchmarkCanMove(b *testing.B) {
+	board := initBoard()
+	state := board.State
+	matrix := state.getMatrix(board.Width, board.Height)
+	startingBlocks := make([]Block, len(state.Pieces))
+
+	for idx, piece := range state.Pieces {
+		startingBlocks[idx], _ = state.getPieceStartingBlock(piece)
+	}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for idx, piece := range state.Pieces {
+			for _, move := range getDirections() {
+				state.canMove(piece, matrix, startingBlocks[idx], move)
+			}
+		}
+	}
+}
+
+func BenchmarkGetMatrix(b *testing.B) {
+	board := initBoard()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		board.State.getMatrix(board.Width, board.Height)
+	}
+}
+
+func BenchmarkFindNewStates(b *testing.B) {
+	board := initBoard()
+	search, _ := board.NewSearch(board.State)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		search.States, search.links = search.States[:1], search.links[:1]
+
+		for hash := range search.VisitedStatesHashes {
+			delete(search.VisitedStatesHashes, hash)
+		}
+
+		search.findNewStates(0)
+	}
+}
+
+func BenchmarkSolveCatalog(b *testing.B) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		board := puzzle.Board()
+
+		b.Run(slug(puzzle.Name), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := board.SolveContext(context.Background(), SolveOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 // Initialises a board
 func initBoard() Board {
 	board := Board{
 		Width:  4,
 		Height: 5,
+		Goal:   Goal{Label: "b", X: 1, Y: 3},
 		State: State{
 			Pieces: []Piece{
 				Piece{
@@ -265,13 +513,7 @@ func initBoard() Board {
 		},
 	}
 
-	board.ZobristHash = board.InitZorbistHash()
-	board.State.Hash = board.GetZobristHash(board.State)
-	board.State.Parent = nil
-	board.States = append(board.States, board.State)
-	board.VisitedStatesHashes = make(map[int]bool, 0)
-
-	return board
+	return NewBoard(board.Width, board.Height, board.State, board.Goal)
 }
 
 // Initialises a board
@@ -279,6 +521,7 @@ func finalBoard() Board {
 	board := Board{
 		Width:  4,
 		Height: 5,
+		Goal:   Goal{Label: "b", X: 1, Y: 3},
 		State: State{
 			Pieces: []Piece{
 				Piece{
@@ -373,11 +616,5 @@ func finalBoard() Board {
 		},
 	}
 
-	board.ZobristHash = board.InitZorbistHash()
-	board.State.Hash = board.GetZobristHash(board.State)
-	board.State.Parent = nil
-	board.States = append(board.States, board.State)
-	board.VisitedStatesHashes = make(map[int]bool, 0)
-
-	return board
+	return NewBoard(board.Width, board.Height, board.State, board.Goal)
 }
@@ -0,0 +1,196 @@
+package klotski
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PieceMove is a single move written in compact notation: a piece label followed by one
+// direction letter per space travelled, i.e. "bD", "aRR" or "hUL".
+type PieceMove struct {
+	Label string
+	Path  []Direction
+}
+
+// Letters used for directions in compact notation.
+const directionLetters = "DRUL"
+
+// Returns a letter used for a move in compact notation.
+func (m *Direction) getLetter() string {
+	switch m.getString() {
+	case "down":
+		return "D"
+	case "right":
+		return "R"
+	case "up":
+		return "U"
+	case "left":
+		return "L"
+	}
+
+	return ""
+}
+
+// Returns a move for a given letter of compact notation.
+func getDirectionFromLetter(letter rune) (Direction, error) {
+	idx := strings.IndexRune(directionLetters, letter)
+
+	if idx < 0 {
+		return Direction{}, fmt.Errorf("Unknown direction %q", letter)
+	}
+
+	return getDirections()[idx], nil
+}
+
+// String returns a move in compact notation.
+func (pm PieceMove) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(pm.Label)
+
+	for _, move := range pm.Path {
+		buffer.WriteString(move.getLetter())
+	}
+
+	return buffer.String()
+}
+
+// ParsePieceMove parses a single move in compact notation, i.e. "aRR".
+// A label is everything before the first direction letter.
+func ParsePieceMove(s string) (PieceMove, error) {
+	idx := strings.IndexAny(s, directionLetters)
+
+	if idx <= 0 {
+		return PieceMove{}, fmt.Errorf("Invalid move %q, want piece label followed by directions", s)
+	}
+
+	pieceMove := PieceMove{Label: s[:idx]}
+
+	for _, letter := range s[idx:] {
+		move, err := getDirectionFromLetter(letter)
+		if err != nil {
+			return PieceMove{}, fmt.Errorf("Invalid move %q: %s", s, err)
+		}
+
+		pieceMove.Path = append(pieceMove.Path, move)
+	}
+
+	return pieceMove, nil
+}
+
+// ParseMoves parses a sequence of moves in compact notation separated by spaces or commas.
+func ParseMoves(s string) ([]PieceMove, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	moves := make([]PieceMove, 0, len(fields))
+
+	for _, field := range fields {
+		move, err := ParsePieceMove(field)
+		if err != nil {
+			return nil, err
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// FormatMoves returns a sequence of moves in compact notation separated by spaces.
+func FormatMoves(moves []PieceMove) string {
+	notations := make([]string, len(moves))
+
+	for idx, move := range moves {
+		notations[idx] = move.String()
+	}
+
+	return strings.Join(notations, " ")
+}
+
+// Notation returns compact notation of moves, i.e. returned by Solve.
+func Notation(moves []Move) []PieceMove {
+	pieceMoves := make([]PieceMove, len(moves))
+
+	for idx, move := range moves {
+		pieceMoves[idx] = move.Notation()
+	}
+
+	return pieceMoves
+}
+
+// Notation returns compact notation of a move.
+func (move Move) Notation() PieceMove {
+	pieceMove := PieceMove{Label: move.Piece.Label, Path: make([]Direction, move.Distance)}
+
+	for idx := range pieceMove.Path {
+		pieceMove.Path[idx] = move.Direction
+	}
+
+	return pieceMove
+}
+
+// String returns a move in compact notation.
+func (move Move) String() string {
+	return move.Notation().String()
+}
+
+// ApplyMoves applies moves in compact notation to a given state.
+// Returns a state after each move or error if any of the moves is not possible.
+func (board *Board) ApplyMoves(state State, moves []PieceMove) ([]State, error) {
+	results := make([]State, 0, len(moves))
+
+	for _, pieceMove := range moves {
+		pieceIdx := state.getPieceIndex(pieceMove.Label)
+
+		if pieceIdx < 0 {
+			return results, fmt.Errorf("Cannot apply %s: unknown piece %q", pieceMove, pieceMove.Label)
+		}
+
+		if len(pieceMove.Path) == 0 {
+			return results, fmt.Errorf("Cannot apply %s: no direction given", pieceMove)
+		}
+
+		newState := state
+
+		for _, move := range pieceMove.Path {
+			piece := newState.Pieces[pieceIdx]
+			startingBlock, _ := newState.getPieceStartingBlock(piece)
+
+			if !newState.canMove(piece, newState.getMatrix(board.Width, board.Height), startingBlock, move) {
+				return results, fmt.Errorf("Cannot apply %s: piece %s cannot move %s", pieceMove, piece.Label, move.getString())
+			}
+
+			newState = board.shiftPiece(newState, pieceIdx, piece, move)
+		}
+
+		results = append(results, newState)
+		state = newState
+	}
+
+	return results, nil
+}
+
+// Returns index of a piece with a given label or -1 if there is no such piece.
+func (state *State) getPieceIndex(label string) int {
+	for idx, piece := range state.Pieces {
+		if piece.Label == label {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// Returns -1, 0 or 1 depending on the sign of a given number.
+func sign(n int) int {
+	if n > 0 {
+		return 1
+	} else if n < 0 {
+		return -1
+	}
+
+	return 0
+}
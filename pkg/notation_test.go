@@ -0,0 +1,118 @@
+package klotski
+
+import (
+	"testing"
+)
+
+func TestParsePieceMove(t *testing.T) {
+	notations := []string{"bD", "aRR", "hUL"}
+
+	for _, notation := range notations {
+		pieceMove, err := ParsePieceMove(notation)
+
+		if err != nil {
+			t.Errorf("Cannot parse move %s, got: %v", notation, err)
+		}
+
+		if pieceMove.String() != notation {
+			t.Errorf("Compact notation of the move incorrect, got: %s, want: %s", pieceMove.String(), notation)
+		}
+	}
+
+	pieceMove, _ := ParsePieceMove("hUL")
+	expectedPath := []Direction{Direction{0, -1}, Direction{-1, 0}}
+
+	if pieceMove.Label != "h" || len(pieceMove.Path) != len(expectedPath) {
+		t.Fatalf("Move parsed incorrectly, got: %+v, want: h %+v", pieceMove, expectedPath)
+	}
+
+	for idx, move := range pieceMove.Path {
+		if move != expectedPath[idx] {
+			t.Errorf("Direction parsed incorrectly, got: %+v, want: %+v", move, expectedPath[idx])
+		}
+	}
+
+	for _, notation := range []string{"", "b", "D", "bX", "bDx"} {
+		if _, err := ParsePieceMove(notation); err == nil {
+			t.Errorf("Error not returned for invalid move %q", notation)
+		}
+	}
+}
+
+func TestParseMoves(t *testing.T) {
+	pieceMoves, err := ParseMoves("jL iRR,\ngD  hUL")
+
+	if err != nil {
+		t.Fatalf("Cannot parse moves, got: %v", err)
+	}
+
+	expected := "jL iRR gD hUL"
+
+	if FormatMoves(pieceMoves) != expected {
+		t.Errorf("Moves parsed incorrectly, got: %s, want: %s", FormatMoves(pieceMoves), expected)
+	}
+}
+
+func TestApplyMoves(t *testing.T) {
+	board := initBoard()
+	pieceMoves, _ := ParseMoves("jLL hD")
+
+	results, err := board.ApplyMoves(board.State, pieceMoves)
+
+	if err != nil {
+		t.Fatalf("Cannot apply moves, got: %v", err)
+	}
+
+	if len(results) != len(pieceMoves) {
+		t.Fatalf("Incorrect number of states, got: %d, want: %d", len(results), len(pieceMoves))
+	}
+
+	last := results[len(results)-1]
+
+	startingBlock, _ := last.getPieceStartingBlock(Piece{Label: "j"})
+
+	if startingBlock != (Block{X: 1, Y: 4}) {
+		t.Errorf("Piece j at incorrect position, got: %+v, want: %+v", startingBlock, Block{X: 1, Y: 4})
+	}
+
+	for _, notation := range []string{"bD", "zD", "jLLL"} {
+		pieceMoves, _ := ParseMoves(notation)
+
+		if _, err := board.ApplyMoves(board.State, pieceMoves); err == nil {
+			t.Errorf("Error not returned for illegal move %s", notation)
+		}
+	}
+}
+
+func TestNotation(t *testing.T) {
+	board := initBoard()
+	initialState := board.State
+
+	results, err := board.Solve()
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	notation := Notation(results)
+
+	if len(notation) != len(results) {
+		t.Fatalf("Incorrect number of moves, got: %d, want: %d", len(notation), len(results))
+	}
+
+	pieceMoves, err := ParseMoves(FormatMoves(notation))
+
+	if err != nil {
+		t.Fatalf("Cannot parse formatted solution, got: %v", err)
+	}
+
+	states, err := board.ApplyMoves(initialState, pieceMoves)
+
+	if err != nil {
+		t.Fatalf("Cannot replay solution, got: %v", err)
+	}
+
+	if !states[len(states)-1].isFinal(board.Goal) {
+		t.Error("Replayed solution does not reach the final state.")
+	}
+}
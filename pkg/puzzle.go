@@ -0,0 +1,464 @@
+package klotski
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Puzzle defines a layout of pieces on a board together with the goal of the puzzle.
+type Puzzle struct {
+	Name   string
+	Tags   []string
+	Moves  int
+	Width  int
+	Height int
+	Goal   Goal
+	Pieces []Piece
+}
+
+// ParsePuzzle parses a puzzle in a text grid format.
+//
+// The grid is preceded by "key: value" headers (name, tags, goal and known optimal number of moves)
+// and a blank line. Each cell of the grid holds a single character label of a piece, "." or "_"
+// marks an empty space. Lines starting with "#" are comments, i.e.
+//
+//	name: Heng Dao Li Ma
+//	tags: huarongdao, classic
+//	goal: b 1 3
+//	moves: 90
+//
+//	a b b c
+//	a b b c
+//	d e e f
+//	d g h f
+//	i . . j
+func ParsePuzzle(text string) (Puzzle, error) {
+	var puzzle Puzzle
+	var rows [][]string
+
+	hasGoal := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if idx := strings.Index(line, ":"); idx >= 0 && len(rows) == 0 {
+			key, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+
+			switch key {
+			case "name":
+				puzzle.Name = value
+			case "tags":
+				for _, tag := range strings.Split(value, ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						puzzle.Tags = append(puzzle.Tags, tag)
+					}
+				}
+			case "moves":
+				moves, err := strconv.Atoi(value)
+				if err != nil {
+					return puzzle, fmt.Errorf("Invalid number of moves %q", value)
+				}
+				puzzle.Moves = moves
+			case "goal":
+				var err error
+				if puzzle.Goal, err = parseGoal(value); err != nil {
+					return puzzle, err
+				}
+				hasGoal = true
+			default:
+				return puzzle, fmt.Errorf("Unknown header %q", key)
+			}
+
+			continue
+		}
+
+		rows = append(rows, strings.Fields(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return puzzle, err
+	}
+
+	if len(rows) == 0 {
+		return puzzle, fmt.Errorf("Puzzle %q has no grid", puzzle.Name)
+	}
+
+	if !hasGoal {
+		return puzzle, fmt.Errorf("Puzzle %q has no goal", puzzle.Name)
+	}
+
+	if err := puzzle.setGrid(rows); err != nil {
+		return puzzle, err
+	}
+
+	return puzzle, nil
+}
+
+// Line separating puzzles in a file of many of them
+const documentSeparator = "---"
+
+// Puzzle in the JSON format: the same headers as the text grid format and rows of the grid,
+// with cells separated by spaces or not, i.e.
+//
+//	{
+//	  "name": "Heng Dao Li Ma",
+//	  "tags": ["huarongdao", "classic"],
+//	  "goal": {"label": "b", "x": 1, "y": 3},
+//	  "moves": 90,
+//	  "grid": ["a b b c", "a b b c", "d e e f", "d g h f", "i . . j"]
+//	}
+type puzzleJSON struct {
+	Name  string   `json:"name,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	Goal  goalJSON `json:"goal"`
+	Moves int      `json:"moves,omitempty"`
+	Grid  []string `json:"grid"`
+}
+
+// Goal of a puzzle in the JSON format.
+type goalJSON struct {
+	Label string `json:"label"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+}
+
+// DecodePuzzle parses a puzzle in the JSON format, if it starts with "{", or in the text grid format otherwise.
+func DecodePuzzle(data []byte) (Puzzle, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var puzzle Puzzle
+
+		if !json.Valid(trimmed) {
+			return Puzzle{}, fmt.Errorf("Invalid puzzle: malformed JSON")
+		}
+
+		if err := json.Unmarshal(trimmed, &puzzle); err != nil {
+			return Puzzle{}, err
+		}
+
+		return puzzle, nil
+	}
+
+	return ParsePuzzle(string(data))
+}
+
+// DecodePuzzles parses a file of puzzles: a JSON array of puzzles, or documents separated by lines of "---",
+// each of them a puzzle in the JSON or text grid format.
+func DecodePuzzles(data []byte) ([]Puzzle, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var puzzles []Puzzle
+
+		if !json.Valid(trimmed) {
+			return nil, fmt.Errorf("Invalid puzzles: malformed JSON")
+		}
+
+		if err := json.Unmarshal(trimmed, &puzzles); err != nil {
+			return nil, err
+		}
+
+		return puzzles, nil
+	}
+
+	var documents [][]byte
+	var document bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if string(bytes.TrimSpace(line)) == documentSeparator {
+			documents = append(documents, append([]byte(nil), document.Bytes()...))
+			document.Reset()
+
+			continue
+		}
+
+		document.Write(line)
+	}
+
+	documents = append(documents, document.Bytes())
+	puzzles := make([]Puzzle, 0, len(documents))
+
+	for idx, content := range documents {
+		// Separators may also start or end a file.
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+
+		puzzle, err := DecodePuzzle(content)
+		if err != nil {
+			return nil, fmt.Errorf("Document %d: %s", idx+1, err)
+		}
+
+		puzzles = append(puzzles, puzzle)
+	}
+
+	return puzzles, nil
+}
+
+// MarshalJSON returns a puzzle in the JSON format.
+func (puzzle Puzzle) MarshalJSON() ([]byte, error) {
+	state := State{Pieces: puzzle.Pieces}
+
+	return json.Marshal(puzzleJSON{
+		Name:  puzzle.Name,
+		Tags:  puzzle.Tags,
+		Goal:  goalJSON{Label: puzzle.Goal.Label, X: puzzle.Goal.X, Y: puzzle.Goal.Y},
+		Moves: puzzle.Moves,
+		Grid:  state.getGrid(puzzle.Width, puzzle.Height),
+	})
+}
+
+// UnmarshalJSON parses a puzzle in the JSON format, with the same checks as ParsePuzzle.
+func (puzzle *Puzzle) UnmarshalJSON(data []byte) error {
+	var decoded puzzleJSON
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("Invalid puzzle: %s", err)
+	}
+
+	parsed := Puzzle{
+		Name:  decoded.Name,
+		Tags:  decoded.Tags,
+		Moves: decoded.Moves,
+		Goal:  Goal{Label: decoded.Goal.Label, X: decoded.Goal.X, Y: decoded.Goal.Y},
+	}
+
+	if len(decoded.Grid) == 0 {
+		return fmt.Errorf("Puzzle %q has no grid", parsed.Name)
+	}
+
+	if parsed.Goal.Label == "" {
+		return fmt.Errorf("Puzzle %q has no goal", parsed.Name)
+	}
+
+	rows := make([][]string, 0, len(decoded.Grid))
+
+	for _, line := range decoded.Grid {
+		row := strings.Fields(line)
+
+		// Labels are single characters, so cells of a row do not have to be separated.
+		if len(row) == 1 {
+			row = strings.Split(row[0], "")
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := parsed.setGrid(rows); err != nil {
+		return err
+	}
+
+	*puzzle = parsed
+
+	return nil
+}
+
+// Limits of sizes of boards of parsed puzzles, so puzzles of untrusted files or requests cannot exhaust memory
+// before their search is even started, i.e. with tables of Zobrist hashes growing with the square of the number
+// of spaces.
+const (
+	MaxBoardWidth  = 16
+	MaxBoardHeight = 16
+	MaxBoardSpaces = 100
+)
+
+// Sets size and pieces of a puzzle from rows of its grid and checks its goal.
+func (puzzle *Puzzle) setGrid(rows [][]string) error {
+	if width, height := len(rows[0]), len(rows); width > MaxBoardWidth || height > MaxBoardHeight || width*height > MaxBoardSpaces {
+		return fmt.Errorf("Puzzle %q: board %dx%d is too large, want: at most %dx%d and %d spaces",
+			puzzle.Name, width, height, MaxBoardWidth, MaxBoardHeight, MaxBoardSpaces)
+	}
+
+	pieces, err := parseGrid(rows)
+	if err != nil {
+		return fmt.Errorf("Puzzle %q: %s", puzzle.Name, err)
+	}
+
+	puzzle.Width, puzzle.Height, puzzle.Pieces = len(rows[0]), len(rows), pieces
+
+	if err := puzzle.validateGoal(); err != nil {
+		return fmt.Errorf("Puzzle %q: %s", puzzle.Name, err)
+	}
+
+	return nil
+}
+
+// Parses a goal in a "label x y" format.
+func parseGoal(value string) (Goal, error) {
+	var goal Goal
+
+	fields := strings.Fields(value)
+
+	if len(fields) != 3 {
+		return goal, fmt.Errorf("Invalid goal %q, want: label x y", value)
+	}
+
+	x, errX := strconv.Atoi(fields[1])
+	y, errY := strconv.Atoi(fields[2])
+
+	if errX != nil || errY != nil {
+		return goal, fmt.Errorf("Invalid goal %q, want: label x y", value)
+	}
+
+	goal.Label, goal.X, goal.Y = fields[0], x, y
+
+	return goal, nil
+}
+
+// Returns pieces found in a grid, in order of their top left blocks.
+func parseGrid(rows [][]string) ([]Piece, error) {
+	var labels []string
+
+	blocks := make(map[string][]Block)
+
+	for y, row := range rows {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("row %d has %d columns, want: %d", y+1, len(row), len(rows[0]))
+		}
+
+		for x, label := range row {
+			if label == "." || label == "_" {
+				continue
+			}
+
+			if len(label) != 1 {
+				return nil, fmt.Errorf("invalid label %q, labels must be single characters", label)
+			}
+
+			if _, ok := blocks[label]; !ok {
+				labels = append(labels, label)
+			}
+
+			blocks[label] = append(blocks[label], Block{X: x, Y: y})
+		}
+	}
+
+	pieces := make([]Piece, 0, len(labels))
+
+	for _, label := range labels {
+		pieceBlocks := blocks[label]
+		first, last := pieceBlocks[0], pieceBlocks[len(pieceBlocks)-1]
+
+		piece := Piece{
+			Label:  label,
+			Width:  last.X - first.X + 1,
+			Height: last.Y - first.Y + 1,
+			Blocks: pieceBlocks,
+		}
+
+		if piece.Width < 1 || piece.Width*piece.Height != len(pieceBlocks) {
+			return nil, fmt.Errorf("piece %s is not a rectangle", label)
+		}
+
+		for idx, block := range pieceBlocks {
+			if block.X != first.X+idx%piece.Width || block.Y != first.Y+idx/piece.Width {
+				return nil, fmt.Errorf("piece %s is not a rectangle", label)
+			}
+		}
+
+		pieces = append(pieces, piece)
+	}
+
+	return pieces, nil
+}
+
+// Checks that the goal piece exists and fits on the board in the goal position.
+func (puzzle *Puzzle) validateGoal() error {
+	for _, piece := range puzzle.Pieces {
+		if piece.Label != puzzle.Goal.Label {
+			continue
+		}
+
+		goal := puzzle.Goal
+
+		if goal.X < 0 || goal.Y < 0 || goal.X+piece.Width > puzzle.Width || goal.Y+piece.Height > puzzle.Height {
+			return fmt.Errorf("goal position of piece %s is outside of the board", goal.Label)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("goal piece %s does not exist", puzzle.Goal.Label)
+}
+
+// Board returns a new board with the initial state of the puzzle.
+func (puzzle *Puzzle) Board() Board {
+	pieces := make([]Piece, len(puzzle.Pieces))
+	copy(pieces, puzzle.Pieces)
+
+	return NewBoard(puzzle.Width, puzzle.Height, State{Pieces: pieces}, puzzle.Goal)
+}
+
+// HasTag checks if a puzzle is tagged with a given tag.
+func (puzzle *Puzzle) HasTag(tag string) bool {
+	for _, t := range puzzle.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns a puzzle in the text grid format accepted by ParsePuzzle.
+func (puzzle Puzzle) String() string {
+	var buffer bytes.Buffer
+
+	if puzzle.Name != "" {
+		buffer.WriteString(fmt.Sprintf("name: %s\n", puzzle.Name))
+	}
+
+	if len(puzzle.Tags) > 0 {
+		buffer.WriteString(fmt.Sprintf("tags: %s\n", strings.Join(puzzle.Tags, ", ")))
+	}
+
+	buffer.WriteString(fmt.Sprintf("goal: %s %d %d\n", puzzle.Goal.Label, puzzle.Goal.X, puzzle.Goal.Y))
+
+	if puzzle.Moves > 0 {
+		buffer.WriteString(fmt.Sprintf("moves: %d\n", puzzle.Moves))
+	}
+
+	buffer.WriteString("\n")
+
+	state := State{Pieces: puzzle.Pieces}
+
+	for _, row := range state.getGrid(puzzle.Width, puzzle.Height) {
+		buffer.WriteString(row + "\n")
+	}
+
+	return buffer.String()
+}
+
+// Returns rows of the grid of a state as in the text grid format, i.e. "i . . j".
+func (state *State) getGrid(width, height int) []string {
+	grid := make([]string, 0, height)
+
+	for _, row := range state.getMatrix(width, height) {
+		grid = append(grid, strings.Replace(strings.Join(row, " "), "_", ".", -1))
+	}
+
+	return grid
+}
+
+// Returns a name of a puzzle suitable for file names and URLs, i.e. "heng-dao-li-ma".
+func slug(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '\'')
+	})
+
+	return strings.Replace(strings.Join(fields, "-"), "'", "", -1)
+}
+
+// Sorts puzzles by name.
+func sortPuzzles(puzzles []Puzzle) {
+	sort.Slice(puzzles, func(i, j int) bool {
+		return puzzles[i].Name < puzzles[j].Name
+	})
+}
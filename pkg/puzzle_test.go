@@ -0,0 +1,162 @@
+package klotski
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParsePuzzle(t *testing.T) {
+	text := `
+# Pieces of the classic layout
+name: Heng Dao Li Ma
+tags: huarongdao, classic
+goal: b 1 3
+moves: 90
+
+a b b c
+a b b c
+d e e f
+d g h f
+i . . j
+`
+	puzzle, err := ParsePuzzle(text)
+
+	if err != nil {
+		t.Fatalf("Cannot parse puzzle, got: %v", err)
+	}
+
+	if puzzle.Width != 4 || puzzle.Height != 5 {
+		t.Errorf("Puzzle has incorrect size, got: %dx%d, want: 4x5", puzzle.Width, puzzle.Height)
+	}
+
+	if puzzle.Moves != 90 || len(puzzle.Tags) != 2 || !puzzle.HasTag("classic") {
+		t.Errorf("Puzzle headers parsed incorrectly, got: %+v", puzzle)
+	}
+
+	board := puzzle.Board()
+	expectedBoard := initBoard()
+
+	if board.Print(board.State) != expectedBoard.Print(expectedBoard.State) {
+		t.Errorf("Puzzle parsed incorrectly, got:\n%s\nwant:\n%s", board.Print(board.State), expectedBoard.Print(expectedBoard.State))
+	}
+
+	for idx, piece := range board.State.Pieces {
+		expectedPiece := expectedBoard.State.Pieces[idx]
+
+		if piece.Label != expectedPiece.Label || piece.Width != expectedPiece.Width || piece.Height != expectedPiece.Height {
+			t.Errorf("Piece parsed incorrectly, got: %+v, want: %+v", piece, expectedPiece)
+		}
+	}
+
+	reparsed, err := ParsePuzzle(puzzle.String())
+
+	if err != nil || reparsed.String() != puzzle.String() {
+		t.Errorf("Puzzle formatted incorrectly, got:\n%s\nwant:\n%s", reparsed.String(), puzzle.String())
+	}
+}
+
+func TestParsePuzzleErrors(t *testing.T) {
+	texts := map[string]string{
+		"no goal":          "a a\n. .\n",
+		"no grid":          "goal: a 0 0\n",
+		"unknown piece":    "goal: z 0 0\n\na a\n. .\n",
+		"goal outside":     "goal: a 1 1\n\na a\n. .\n",
+		"uneven rows":      "goal: a 0 0\n\na a\n.\n",
+		"not a rectangle":  "goal: a 0 0\n\na a\na .\n",
+		"unknown header":   "size: 2\ngoal: a 0 0\n\na a\n. .\n",
+		"long label":       "goal: ab 0 0\n\nab .\n. .\n",
+		"invalid goal":     "goal: a 0\n\na a\n. .\n",
+		"invalid moves":    "goal: a 0 0\nmoves: many\n\na a\n. .\n",
+		"split horizontal": "goal: a 0 0\n\na . a\n. . .\n",
+		"too wide":         "goal: a 0 0\n\na" + strings.Repeat(" .", MaxBoardWidth) + "\n",
+		"too many spaces":  "goal: a 0 0\n\na" + strings.Repeat(" .", MaxBoardWidth-1) + "\n" + strings.Repeat(strings.Repeat(". ", MaxBoardWidth)+"\n", MaxBoardSpaces/MaxBoardWidth),
+	}
+
+	for name, text := range texts {
+		if _, err := ParsePuzzle(text); err == nil {
+			t.Errorf("Error not returned for puzzle with %s", name)
+		}
+	}
+}
+
+func TestDecodePuzzle(t *testing.T) {
+	puzzles, _ := Catalog()
+
+	for _, puzzle := range puzzles {
+		data, err := json.Marshal(puzzle)
+		if err != nil {
+			t.Fatalf("Cannot encode puzzle %s, got: %v", puzzle.Name, err)
+		}
+
+		for _, encoded := range [][]byte{data, []byte(puzzle.String())} {
+			decoded, err := DecodePuzzle(encoded)
+
+			if err != nil || decoded.String() != puzzle.String() {
+				t.Errorf("Puzzle %s decoded incorrectly from %s, got: %q, %v, want: %q", puzzle.Name, encoded, decoded.String(), err, puzzle.String())
+			}
+		}
+	}
+
+	compact := `{"goal": {"label": "a", "x": 0, "y": 1}, "grid": ["aa.", "aa.", "..."]}`
+
+	if puzzle, err := DecodePuzzle([]byte(compact)); err != nil || puzzle.Width != 3 || len(puzzle.Pieces) != 1 {
+		t.Errorf("Puzzle with compact rows decoded incorrectly, got: %+v, %v", puzzle, err)
+	}
+}
+
+func TestDecodePuzzleErrors(t *testing.T) {
+	texts := map[string]string{
+		"malformed JSON":  `{"goal": `,
+		"no goal":         `{"grid": ["aa", ".."]}`,
+		"no grid":         `{"goal": {"label": "a", "x": 0, "y": 0}}`,
+		"not a rectangle": `{"goal": {"label": "a", "x": 0, "y": 0}, "grid": ["aa", "a."]}`,
+		"goal outside":    `{"goal": {"label": "a", "x": 1, "y": 1}, "grid": ["aa", ".."]}`,
+	}
+
+	for name, text := range texts {
+		if _, err := DecodePuzzle([]byte(text)); err == nil {
+			t.Errorf("Error not returned for puzzle with %s", name)
+		}
+	}
+}
+
+func TestDecodePuzzles(t *testing.T) {
+	puzzles, _ := Catalog()
+	data, _ := json.Marshal(puzzles)
+
+	var documents []string
+
+	for _, puzzle := range puzzles {
+		documents = append(documents, puzzle.String())
+	}
+
+	for _, encoded := range []string{string(data), strings.Join(documents, "---\n"), "---\n" + strings.Join(documents, "\n---\n\n") + "---\n"} {
+		decoded, err := DecodePuzzles([]byte(encoded))
+
+		if err != nil || len(decoded) != len(puzzles) {
+			t.Fatalf("Puzzles decoded incorrectly from %q, got: %d, %v, want: %d", encoded, len(decoded), err, len(puzzles))
+		}
+
+		for idx, puzzle := range decoded {
+			if puzzle.String() != puzzles[idx].String() {
+				t.Errorf("Puzzle %d decoded incorrectly, got: %q, want: %q", idx+1, puzzle.String(), puzzles[idx].String())
+			}
+		}
+	}
+
+	if _, err := DecodePuzzles([]byte(documents[0] + "---\ngoal: a 0 0\n")); err == nil {
+		t.Error("Error not returned for an invalid document")
+	}
+}
+
+func TestPrintExit(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 0 1\n\na a .\na a .\n. . .\n")
+	board := puzzle.Board()
+
+	expected := "X X X X X \nX a a _ X \nZ a a _ X \nZ _ _ _ X \nX Z Z X X \n"
+
+	if board.Print(board.State) != expected {
+		t.Errorf("Board printed incorrectly, got:\n%s\nwant:\n%s", board.Print(board.State), expected)
+	}
+}
@@ -0,0 +1,251 @@
+package klotski
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"time"
+)
+
+// Default number of frames of a move in animations drawn by RasterRenderer.Animate
+const DefaultFramesPerMove = 8
+
+// Delay of the first and the last frame of animations, so the initial and the solved state can be seen
+const animationPause = time.Second
+
+// RasterRenderer draws states of boards as paletted images, ready to be encoded with image/png or image/gif:
+// pieces as rounded rectangles in the same colors as SVGRenderer uses, the highlighted piece in white and the exit
+// in green. Labels of pieces are not drawn.
+type RasterRenderer struct {
+	// Size of a space in pixels, DefaultCellSize if it is 0.
+	CellSize int
+}
+
+// Palette of images drawn by RasterRenderer, with the background of frames first
+var rasterPalette = getRasterPalette()
+
+// Draw returns an image of a state of a board with a piece of a given label highlighted.
+func (renderer RasterRenderer) Draw(board *Board, state State, highlight string) *image.Paletted {
+	img := image.NewPaletted(image.Rectangle{Max: renderer.getBoardSize(board)}, rasterPalette)
+
+	renderer.drawBoard(img, image.Point{}, board, state, highlight, -1, image.Point{})
+
+	return img
+}
+
+// DrawSolution returns a sprite sheet of a solution given as moves in compact notation and states after them:
+// the initial state and the state after each move, with the moved piece highlighted, in rows of a given number
+// of frames (DefaultColumns if it is not positive).
+func (renderer RasterRenderer) DrawSolution(board *Board, initialState State, notation []PieceMove, states []State, columns int) *image.Paletted {
+	if columns <= 0 {
+		columns = DefaultColumns
+	}
+
+	if columns > len(states)+1 {
+		columns = len(states) + 1
+	}
+
+	boardSize := renderer.getBoardSize(board)
+	spacing := renderer.getCellSize() / 2
+	frame := boardSize.Add(image.Pt(spacing, spacing))
+	rows := (len(states) + columns) / columns
+
+	img := image.NewPaletted(image.Rect(0, 0, columns*frame.X-spacing, rows*frame.Y-spacing), rasterPalette)
+
+	for step := 0; step <= len(states); step++ {
+		state, highlight := initialState, ""
+
+		if step > 0 {
+			state, highlight = states[step-1], notation[step-1].Label
+		}
+
+		origin := image.Pt(step%columns*frame.X, step/columns*frame.Y)
+		renderer.drawBoard(img, origin, board, state, highlight, -1, image.Point{})
+	}
+
+	return img
+}
+
+// Animate returns an animation of a solution given as moves in compact notation and states after them.
+// Moved pieces slide smoothly along their paths over a given number of frames per move (DefaultFramesPerMove
+// if it is not positive), each move taking a given time. The animation loops forever.
+func (renderer RasterRenderer) Animate(board *Board, initialState State, notation []PieceMove, states []State, frames int, delay time.Duration) *gif.GIF {
+	if frames <= 0 {
+		frames = DefaultFramesPerMove
+	}
+
+	size := renderer.getCellSize()
+	animation := &gif.GIF{}
+
+	// Delays of GIF frames are given in hundredths of a second, and browsers slow down ones shorter than 2.
+	frameDelay := int(delay / time.Duration(frames) / (10 * time.Millisecond))
+
+	if frameDelay < 2 {
+		frameDelay = 2
+	}
+
+	boardSize := renderer.getBoardSize(board)
+	animation.Config = image.Config{ColorModel: rasterPalette, Width: boardSize.X, Height: boardSize.Y}
+
+	var previous *image.Paletted
+
+	// Frames after the first one only hold the part changed since the previous frame, drawn over it.
+	addFrame := func(state State, highlight string, moving int, offset image.Point, delay int) {
+		img := image.NewPaletted(image.Rectangle{Max: boardSize}, rasterPalette)
+		renderer.drawBoard(img, image.Point{}, board, state, highlight, moving, offset)
+
+		frame := img
+
+		if previous != nil {
+			frame = img.SubImage(getChangedBounds(previous, img)).(*image.Paletted)
+		}
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+		animation.Disposal = append(animation.Disposal, gif.DisposalNone)
+		previous = img
+	}
+
+	addFrame(initialState, "", -1, image.Point{}, int(animationPause/(10*time.Millisecond)))
+
+	before := initialState
+
+	for step, pieceMove := range notation {
+		moving := before.getPieceIndex(pieceMove.Label)
+
+		// Positions of the piece in pixels after each space of its path, relative to the one before the move.
+		path := make([]image.Point, len(pieceMove.Path)+1)
+
+		for idx, direction := range pieceMove.Path {
+			path[idx+1] = path[idx].Add(image.Pt(direction.X*size, direction.Y*size))
+		}
+
+		for frame := 1; frame < frames; frame++ {
+			// Distance travelled along the path in spaces, split into the whole spaces and a fraction of the next one.
+			travelled := float64(frame*len(pieceMove.Path)) / float64(frames)
+			space := int(travelled)
+			fraction := travelled - float64(space)
+			offset := path[space].Add(path[space+1].Sub(path[space]).Mul(int(fraction * 1000)).Div(1000))
+
+			addFrame(before, pieceMove.Label, moving, offset, frameDelay)
+		}
+
+		addFrame(states[step], pieceMove.Label, -1, image.Point{}, frameDelay)
+		before = states[step]
+	}
+
+	animation.Delay[len(animation.Delay)-1] += int(animationPause / (10 * time.Millisecond))
+
+	return animation
+}
+
+// Draws a state of a board with its top left corner at a given point. A piece of a given index, if it is not -1,
+// is drawn moved by an offset in pixels.
+func (renderer RasterRenderer) drawBoard(img *image.Paletted, origin image.Point, board *Board, state State, highlight string, moving int, offset image.Point) {
+	size := renderer.getCellSize()
+	wall := getWallSize(size)
+	bounds := image.Rectangle{Max: renderer.getBoardSize(board)}.Add(origin)
+
+	fillRoundedRect(img, bounds, wall/2, wallColor)
+	fillRoundedRect(img, bounds.Inset(wall), 0, backgroundColor)
+
+	for _, exit := range board.getExitRects(size) {
+		fillRoundedRect(img, exit.Add(origin), 0, getPaletteColor(exitColor))
+	}
+
+	for pieceIdx, piece := range state.Pieces {
+		fill := getPaletteColor(getPieceColor(board, state, pieceIdx))
+
+		if piece.Label == highlight {
+			fill = getPaletteColor(highlightColor)
+		}
+
+		pieceBounds := getPieceBounds(piece, size, wall).Add(origin)
+
+		if pieceIdx == moving {
+			pieceBounds = pieceBounds.Add(offset)
+		}
+
+		fillRoundedRect(img, pieceBounds, size/6, outlineColor)
+		fillRoundedRect(img, pieceBounds.Inset(getStrokeWidth(size)), size/6-getStrokeWidth(size), fill)
+	}
+}
+
+// Returns the size of a space in pixels.
+func (renderer RasterRenderer) getCellSize() int {
+	return SVGRenderer{CellSize: renderer.CellSize}.getCellSize()
+}
+
+// Returns the size of a board with walls around it in pixels.
+func (renderer RasterRenderer) getBoardSize(board *Board) image.Point {
+	width, height := SVGRenderer{CellSize: renderer.CellSize}.getBoardSize(board)
+
+	return image.Pt(width, height)
+}
+
+// Fills a rectangle with corners rounded by a given radius with a color of the palette.
+func fillRoundedRect(img *image.Paletted, rect image.Rectangle, radius int, c color.RGBA) {
+	idx := uint8(img.Palette.Index(c))
+	rect = rect.Intersect(img.Rect)
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Distances from the centre of the nearest corner circle, if the pixel is in a corner.
+			dx, dy := 0, 0
+
+			if x < rect.Min.X+radius {
+				dx = rect.Min.X + radius - x
+			} else if x >= rect.Max.X-radius {
+				dx = x - (rect.Max.X - radius - 1)
+			}
+
+			if y < rect.Min.Y+radius {
+				dy = rect.Min.Y + radius - y
+			} else if y >= rect.Max.Y-radius {
+				dy = y - (rect.Max.Y - radius - 1)
+			}
+
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetColorIndex(x, y, idx)
+			}
+		}
+	}
+}
+
+// Returns the bounds of pixels which differ between two images of the same size, at least one pixel.
+func getChangedBounds(a, b *image.Paletted) image.Rectangle {
+	changed := image.Rectangle{}
+
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		for x := a.Rect.Min.X; x < a.Rect.Max.X; x++ {
+			if a.ColorIndexAt(x, y) != b.ColorIndexAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	if changed.Empty() {
+		return image.Rect(0, 0, 1, 1)
+	}
+
+	return changed
+}
+
+// Returns the palette of images drawn by RasterRenderer.
+func getRasterPalette() color.Palette {
+	palette := color.Palette{
+		color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		backgroundColor,
+		wallColor,
+		outlineColor,
+		getPaletteColor(exitColor),
+		getPaletteColor(highlightColor),
+		getPaletteColor(goalColor),
+	}
+
+	for _, code := range pieceColors {
+		palette = append(palette, getPaletteColor(code))
+	}
+
+	return palette
+}
@@ -0,0 +1,110 @@
+package klotski
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestRasterRenderer(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 0 1\n\na a .\na a b\n. . b\n")
+	board := puzzle.Board()
+	renderer := RasterRenderer{CellSize: 20}
+
+	img := renderer.Draw(&board, board.State, "b")
+
+	// Spaces are 20 pixels wide, walls 5 pixels thick.
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"wall", 2, 10, wallColor},
+		{"exit", 2, 40, getPaletteColor(exitColor)},
+		{"goal piece", 25, 25, getPaletteColor(goalColor)},
+		{"highlighted piece", 55, 45, getPaletteColor(highlightColor)},
+		{"empty space", 55, 15, backgroundColor},
+	}
+
+	for _, test := range tests {
+		if got := img.At(test.x, test.y); got != test.want {
+			t.Errorf("Incorrect color of %s at %d,%d, got: %v, want: %v", test.name, test.x, test.y, got, test.want)
+		}
+	}
+
+	if err := png.Encode(&bytes.Buffer{}, img); err != nil {
+		t.Errorf("Cannot encode image, got: %v", err)
+	}
+
+	moves := board.Moves(board.State)
+	states := make([]State, len(moves))
+
+	for idx, move := range moves {
+		states[idx] = move.After
+	}
+
+	sheet := renderer.DrawSolution(&board, board.State, Notation(moves), states, 2)
+	rows := (len(moves) + 2) / 2
+
+	if size := sheet.Bounds().Size(); size.X != 2*70+10 || size.Y != rows*80-10 {
+		t.Errorf("Incorrect size of the sprite sheet, got: %v", size)
+	}
+}
+
+func TestRasterRendererAnimate(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 0 1\n\na a .\na a b\n. . .\n")
+	board := puzzle.Board()
+	renderer := RasterRenderer{CellSize: 20}
+
+	pieceMoves, _ := ParseMoves("bU bDDL")
+	states, err := board.ApplyMoves(board.State, pieceMoves)
+	if err != nil {
+		t.Fatalf("Cannot apply moves, got: %v", err)
+	}
+
+	animation := renderer.Animate(&board, board.State, pieceMoves, states, 6, 400*time.Millisecond)
+
+	if len(animation.Image) != 1+2*6 || animation.Delay[0] != 100 || animation.Delay[1] != 6 || animation.Delay[12] != 106 {
+		t.Fatalf("Incorrect frames, got: %d, delays %v", len(animation.Image), animation.Delay)
+	}
+
+	tests := []struct {
+		name  string
+		frame int
+		x, y  int
+		want  color.RGBA
+	}{
+		// Halfway through the first move piece b is half a space higher.
+		{"piece b moving up", 3, 55, 32, getPaletteColor(highlightColor)},
+		{"space left by piece b", 3, 55, 38, backgroundColor},
+		// Piece b turns left after 2 spaces down, so it is half a space to the left of the bottom right space.
+		{"piece b turning left", 11, 45, 55, getPaletteColor(highlightColor)},
+		{"space left by piece b after turning", 11, 58, 55, backgroundColor},
+		{"piece b after the last move", 12, 35, 55, getPaletteColor(highlightColor)},
+	}
+
+	// Frames only hold parts changed since previous frames, so they are drawn one over another.
+	frames := make([]*image.RGBA, len(animation.Image))
+	canvas := image.NewRGBA(image.Rect(0, 0, animation.Config.Width, animation.Config.Height))
+
+	for idx, frame := range animation.Image {
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Src)
+		frames[idx] = image.NewRGBA(canvas.Bounds())
+		draw.Draw(frames[idx], canvas.Bounds(), canvas, image.Point{}, draw.Src)
+	}
+
+	for _, test := range tests {
+		if got := frames[test.frame].RGBAAt(test.x, test.y); got != test.want {
+			t.Errorf("Incorrect color of %s in frame %d, got: %v, want: %v", test.name, test.frame, got, test.want)
+		}
+	}
+
+	if err := gif.EncodeAll(&bytes.Buffer{}, animation); err != nil {
+		t.Errorf("Cannot encode animation, got: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package klotski
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+)
+
+// Renderer renders a state of a board as text, with a piece of a given label highlighted, i.e. the one moved last.
+// An empty label highlights no piece.
+type Renderer interface {
+	Render(board *Board, state State, highlight string) string
+}
+
+// TextRenderer renders states in plain ASCII, the same way as Board.Print: "X" for walls, "Z" for the exit,
+// "_" for empty spaces and labels of pieces. It cannot highlight pieces.
+type TextRenderer struct{}
+
+// ANSIRenderer renders states for terminals: pieces filled with distinct colors of the 256 color palette
+// and outlined with Unicode box-drawing characters, the highlighted piece in white and the exit in green.
+type ANSIRenderer struct{}
+
+// Background colors of pieces in the 256 color palette, taken in turn by pieces other than the goal one
+var pieceColors = []int{33, 70, 166, 134, 37, 178, 99, 208, 30, 161, 105, 142}
+
+// Colors of the goal piece, the highlighted piece, labels and the exit in the 256 color palette
+const (
+	goalColor      = 196
+	highlightColor = 231
+	labelColor     = 231
+	highlightLabel = 16
+	exitColor      = 34
+)
+
+// ANSI escape sequences used by ANSIRenderer
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+)
+
+// Box-drawing characters of corners of spaces, indexed by lines going from them: 1 up, 2 right, 4 down and 8 left
+var boxCorners = []rune(" ╵╶└╷│┌├╴┘─┴┐┤┬┼")
+
+// Render returns a state of a board in plain ASCII.
+func (TextRenderer) Render(board *Board, state State, highlight string) string {
+	return board.Print(state)
+}
+
+// Render returns a state of a board in ANSI colors. Every space is three characters wide and one line high,
+// with lines of outlines between spaces.
+func (renderer ANSIRenderer) Render(board *Board, state State, highlight string) string {
+	var buffer bytes.Buffer
+
+	// Owners of spaces: indexes of pieces, -1 for empty spaces and -2 for walls around the board.
+	owners := make([][]int, board.Height)
+
+	for y := range owners {
+		owners[y] = make([]int, board.Width)
+
+		for x := range owners[y] {
+			owners[y][x] = -1
+		}
+	}
+
+	for pieceIdx, piece := range state.Pieces {
+		for _, block := range piece.Blocks {
+			owners[block.Y][block.X] = pieceIdx
+		}
+	}
+
+	// Labels are written in the first space of each piece in reading order.
+	labels := make(map[Block]string, len(state.Pieces))
+
+	for _, piece := range state.Pieces {
+		labels[getLabelBlock(piece)] = piece.Label
+	}
+
+	owner := func(x, y int) int {
+		if x < 0 || y < 0 || x >= board.Width || y >= board.Height {
+			return -2
+		}
+
+		return owners[y][x]
+	}
+
+	// Edges are drawn between spaces of different owners, except between empty spaces and walls outside of the board.
+	isEdge := func(a, b int) bool {
+		return a != b && !(a == -2 && b == -2)
+	}
+
+	// Horizontal edge above a space and vertical edge on the left of it.
+	isTopEdge := func(x, y int) bool {
+		return x >= 0 && x < board.Width && isEdge(owner(x, y-1), owner(x, y))
+	}
+
+	isLeftEdge := func(x, y int) bool {
+		return y >= 0 && y < board.Height && isEdge(owner(x-1, y), owner(x, y))
+	}
+
+	// Parts of the exit: a horizontal edge above a space and a vertical edge on the left of it.
+	isTopExit := func(x, y int) bool {
+		return (y == 0 && board.IsExit(x, -1)) || (y == board.Height && board.IsExit(x, board.Height))
+	}
+
+	isLeftExit := func(x, y int) bool {
+		return (x == 0 && board.IsExit(-1, y)) || (x == board.Width && board.IsExit(board.Width, y))
+	}
+
+	fill := func(owner int) string {
+		switch {
+		case owner < 0:
+			return ansiReset
+		case state.Pieces[owner].Label == highlight:
+			return fmt.Sprintf("\x1b[48;5;%dm\x1b[38;5;%dm%s", highlightColor, highlightLabel, ansiBold)
+		}
+
+		return fmt.Sprintf("\x1b[48;5;%dm\x1b[38;5;%dm", getPieceColor(board, state, owner), labelColor)
+	}
+
+	exit := fmt.Sprintf("\x1b[38;5;%dm%s", exitColor, ansiBold)
+
+	for y := 0; y <= board.Height; y++ {
+		// Line of corners and horizontal edges above the row of spaces.
+		for x := 0; x <= board.Width; x++ {
+			corner := 0
+
+			if isLeftEdge(x, y-1) {
+				corner |= 1
+			}
+
+			if isTopEdge(x, y) {
+				corner |= 2
+			}
+
+			if isLeftEdge(x, y) {
+				corner |= 4
+			}
+
+			if isTopEdge(x-1, y) {
+				corner |= 8
+			}
+
+			switch {
+			case corner == 0:
+				buffer.WriteString(fill(owner(x, y)) + " ")
+			case corner == 10 && isTopExit(x-1, y) && isTopExit(x, y):
+				buffer.WriteString(ansiReset + exit + "━")
+			case corner == 5 && isLeftExit(x, y-1) && isLeftExit(x, y):
+				buffer.WriteString(ansiReset + exit + "┃")
+			default:
+				buffer.WriteString(ansiReset + string(boxCorners[corner]))
+			}
+
+			if x == board.Width {
+				break
+			}
+
+			switch {
+			case isTopExit(x, y):
+				buffer.WriteString(ansiReset + exit + "━━━")
+			case isTopEdge(x, y):
+				buffer.WriteString(ansiReset + "───")
+			default:
+				buffer.WriteString(fill(owner(x, y)) + "   ")
+			}
+		}
+
+		buffer.WriteString(ansiReset + "\n")
+
+		if y == board.Height {
+			break
+		}
+
+		// Row of spaces and vertical edges between them, with labels of pieces.
+		for x := 0; x <= board.Width; x++ {
+			switch {
+			case isLeftExit(x, y):
+				buffer.WriteString(ansiReset + exit + "┃")
+			case isLeftEdge(x, y):
+				buffer.WriteString(ansiReset + "│")
+			default:
+				buffer.WriteString(fill(owner(x, y)) + " ")
+			}
+
+			if x == board.Width {
+				break
+			}
+
+			label, ok := labels[Block{X: x, Y: y}]
+
+			if !ok {
+				label = " "
+			}
+
+			buffer.WriteString(fill(owner(x, y)) + " " + label + " ")
+		}
+
+		buffer.WriteString(ansiReset + "\n")
+	}
+
+	return buffer.String()
+}
+
+// Returns the color of a piece of a given index in the 256 color palette: the goal piece is red, others take
+// colors of pieceColors in turn.
+func getPieceColor(board *Board, state State, pieceIdx int) int {
+	if state.Pieces[pieceIdx].Label == board.Goal.Label {
+		return goalColor
+	}
+
+	return pieceColors[pieceIdx%len(pieceColors)]
+}
+
+// Returns a color of the 6x6x6 cube or the grayscale ramp of the 256 color palette, so images use the same
+// colors as terminals.
+func getPaletteColor(code int) color.RGBA {
+	if code >= 232 {
+		level := uint8(8 + (code-232)*10)
+
+		return color.RGBA{R: level, G: level, B: level, A: 255}
+	}
+
+	levels := []uint8{0, 95, 135, 175, 215, 255}
+	code -= 16
+
+	return color.RGBA{R: levels[code/36], G: levels[code/6%6], B: levels[code%6], A: 255}
+}
+
+// Returns the block of a piece its label is written in: the first one in reading order.
+func getLabelBlock(piece Piece) Block {
+	first := piece.Blocks[0]
+
+	for _, block := range piece.Blocks {
+		if block.Y < first.Y || (block.Y == first.Y && block.X < first.X) {
+			first = block
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,49 @@
+package klotski
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTextRenderer(t *testing.T) {
+	puzzle, _ := FindPuzzle("Heng Dao Li Ma")
+	board := puzzle.Board()
+
+	if rendered := (TextRenderer{}).Render(&board, board.State, "b"); rendered != board.Print(board.State) {
+		t.Errorf("Board rendered incorrectly, got:\n%s\nwant:\n%s", rendered, board.Print(board.State))
+	}
+}
+
+func TestANSIRenderer(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 0 1\n\na a .\na a b\n. . b\n")
+	board := puzzle.Board()
+	escapes := regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+	expected := strings.Join([]string{
+		"┌───────┬───┐",
+		"│ a     │   │",
+		"│       ├───┤",
+		"┃       │ b │",
+		"├───────┤   │",
+		"┃       │   │",
+		"└━━━━━━━┴───┘",
+		"",
+	}, "\n")
+
+	rendered := ANSIRenderer{}.Render(&board, board.State, "")
+
+	if plain := escapes.ReplaceAllString(rendered, ""); plain != expected {
+		t.Errorf("Board rendered incorrectly, got:\n%s\nwant:\n%s", plain, expected)
+	}
+
+	highlighted := ANSIRenderer{}.Render(&board, board.State, "b")
+
+	if strings.Contains(rendered, "\x1b[48;5;231m") || !strings.Contains(highlighted, "\x1b[48;5;231m") {
+		t.Error("Piece b highlighted incorrectly")
+	}
+
+	if escapes.ReplaceAllString(highlighted, "") != expected {
+		t.Errorf("Highlighted board rendered incorrectly, got:\n%s", escapes.ReplaceAllString(highlighted, ""))
+	}
+}
@@ -0,0 +1,96 @@
+package klotski
+
+import "time"
+
+// ReportVersion is the version of the schema of Report, bumped whenever a field is renamed, removed or changes
+// its meaning. New fields may be added without a new version.
+const ReportVersion = 1
+
+// Report is a solution of a puzzle in a form meant for other tools, with a stable JSON schema.
+type Report struct {
+	Version int    `json:"version"`
+	Puzzle  Puzzle `json:"puzzle"`
+	// Metric of moves, "slide-1-2": a piece sliding by one or two spaces in the same direction is a single move.
+	Metric string       `json:"metric"`
+	Moves  []ReportMove `json:"moves"`
+	Stats  ReportStats  `json:"stats"`
+	// Whether the solution has been taken from a cache, in which case only the depth of stats is known.
+	Cached bool `json:"cached"`
+}
+
+// ReportMove is a single move of a Report.
+type ReportMove struct {
+	// Number of the move, starting from 1.
+	Step      int    `json:"step"`
+	Piece     string `json:"piece"`
+	Direction string `json:"direction"`
+	Distance  int    `json:"distance"`
+	// Move in compact notation, i.e. "jLL".
+	Notation string `json:"notation"`
+	// Starting block (top left one) of the piece before and after the move.
+	From ReportBlock `json:"from"`
+	To   ReportBlock `json:"to"`
+	// Rows of the board after the move, as in the text grid format.
+	Board []string `json:"board"`
+}
+
+// ReportBlock is a position on the board, counted from the top left corner.
+type ReportBlock struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ReportStats are statistics of the search of a Report.
+type ReportStats struct {
+	Depth         int     `json:"depth"`
+	NodesExpanded int     `json:"nodes_expanded"`
+	Visited       int     `json:"visited"`
+	FrontierSize  int     `json:"frontier_size"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+}
+
+// NewReport returns a report of a solution of a puzzle, solved from its initial state.
+func NewReport(puzzle Puzzle, solution Solution) Report {
+	board := puzzle.Board()
+	moves := make([]ReportMove, len(solution.Moves))
+
+	for idx, move := range solution.Moves {
+		moves[idx] = board.NewReportMove(idx+1, move)
+	}
+
+	return Report{
+		Version: ReportVersion,
+		Puzzle:  puzzle,
+		Metric:  MoveMetric,
+		Moves:   moves,
+		Stats:   NewReportStats(solution.Stats),
+		Cached:  solution.Cached,
+	}
+}
+
+// NewReportMove returns a move of a report with a given number.
+func (board *Board) NewReportMove(step int, move Move) ReportMove {
+	from, _ := move.Before.getPieceStartingBlock(move.Piece)
+
+	return ReportMove{
+		Step:      step,
+		Piece:     move.Piece.Label,
+		Direction: move.Direction.String(),
+		Distance:  move.Distance,
+		Notation:  move.String(),
+		From:      ReportBlock{X: from.X, Y: from.Y},
+		To:        ReportBlock{X: from.X + move.Direction.X*move.Distance, Y: from.Y + move.Direction.Y*move.Distance},
+		Board:     move.After.getGrid(board.Width, board.Height),
+	}
+}
+
+// NewReportStats returns statistics of a search in a report.
+func NewReportStats(stats Stats) ReportStats {
+	return ReportStats{
+		Depth:         stats.Depth,
+		NodesExpanded: stats.NodesExpanded,
+		Visited:       stats.Visited,
+		FrontierSize:  stats.FrontierSize,
+		ElapsedMs:     float64(stats.Elapsed) / float64(time.Millisecond),
+	}
+}
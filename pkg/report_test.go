@@ -0,0 +1,47 @@
+package klotski
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	puzzle, _ := FindPuzzle("Heng Dao Li Ma")
+	board := puzzle.Board()
+
+	moves, err := board.Solve()
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	report := NewReport(puzzle, Solution{Moves: moves, Stats: Stats{Depth: len(moves)}})
+
+	if report.Version != ReportVersion || report.Metric != MoveMetric || len(report.Moves) != puzzle.Moves {
+		t.Errorf("Incorrect report, got: version %d, metric %s, %d moves", report.Version, report.Metric, len(report.Moves))
+	}
+
+	for idx, move := range report.Moves {
+		if move.Step != idx+1 || move.Notation != moves[idx].String() || move.Distance != moves[idx].Distance {
+			t.Errorf("Incorrect move %d, got: %+v, want: %s", idx+1, move, moves[idx])
+		}
+	}
+
+	// The goal piece b is in the goal position, column 1 and row 3.
+	last := report.Moves[len(report.Moves)-1]
+
+	if len(last.Board) != board.Height || last.Board[3][2:5] != "b b" || last.To != (ReportBlock{X: 1, Y: 3}) {
+		t.Errorf("Incorrect board after the last move, got: %q", last.Board)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Cannot encode report, got: %v", err)
+	}
+
+	for _, key := range []string{`"version":1`, `"metric":"slide-1-2"`, `"grid":[`, `"from":{"x":`, `"nodes_expanded":`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("Encoded report does not contain %s, got: %s", key, data)
+		}
+	}
+}
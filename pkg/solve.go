@@ -0,0 +1,321 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// How often (in expanded states) the search checks its context and reports progress.
+const contextCheckInterval = 1 << 10
+
+// Approximate size in bytes of an entry of the maps of visited states, including the overhead of their buckets.
+const visitedEntrySize = 32
+
+// Default intervals between progress reports and saving checkpoints.
+const (
+	defaultProgressInterval   = 500 * time.Millisecond
+	defaultCheckpointInterval = time.Minute
+)
+
+var (
+	// ErrNodeLimit is returned (wrapped in LimitError) when the search expanded the maximum number of states.
+	ErrNodeLimit = errors.New("Maximum number of expanded states reached")
+
+	// ErrMemoryLimit is returned (wrapped in LimitError) when the search exceeded its memory budget.
+	ErrMemoryLimit = errors.New("Maximum memory usage reached")
+
+	// ErrNoSolution is returned when the final state cannot be reached from the initial state.
+	ErrNoSolution = errors.New("Cannot solve")
+)
+
+// Algorithm defines how a search explores the states of a board.
+type Algorithm string
+
+const (
+	// AlgorithmBFS is a breadth-first search keeping all states in memory, used by default.
+	AlgorithmBFS Algorithm = "bfs"
+	// AlgorithmExternal is a breadth-first search keeping states in sorted files on disk,
+	// for boards with more states than fit in memory.
+	AlgorithmExternal Algorithm = "external"
+	// AlgorithmFrontier is a breadth-first search keeping only the last two layers of states in memory,
+	// and the depth of each visited state for reconstructing the solution.
+	AlgorithmFrontier Algorithm = "frontier"
+)
+
+// SolveOptions defines the algorithm and limits of a search. Zero values mean no limit.
+type SolveOptions struct {
+	// Algorithm of the search, AlgorithmBFS by default.
+	Algorithm Algorithm
+	// Directory for temporary files of the external search, the default temporary directory if empty.
+	TempDir string
+	// Maximum number of states expanded by the search.
+	MaxNodes int
+	// Maximum memory used by the search in bytes, estimated from the number of states and visited states
+	// it keeps in memory. States of the external search are kept on disk, so only its buffer is counted.
+	MaxMemory uint64
+	// Progress is called periodically with statistics of the search and once more when it ends.
+	Progress func(Stats)
+	// Interval between progress reports, 500ms by default.
+	ProgressInterval time.Duration
+	// Path of a file the search is periodically saved to, so it can be resumed with LoadCheckpoint.
+	// Only supported by AlgorithmBFS.
+	// The search is also saved when it is stopped by its context or any of the limits.
+	Checkpoint string
+	// Interval between saving checkpoints, 1 minute by default.
+	CheckpointInterval time.Duration
+	// Cache of solutions looked up before the search and updated with the solution it finds.
+	Cache *SolutionCache
+}
+
+// Stats holds statistics of a search.
+type Stats struct {
+	// Number of states expanded so far.
+	NodesExpanded int
+	// Number of states waiting to be expanded.
+	FrontierSize int
+	// Number of moves leading to the state being expanded.
+	Depth int
+	// Number of distinct states seen so far.
+	Visited int
+	Elapsed time.Duration
+}
+
+// Solution holds moves leading from the initial state to the final state and statistics of the search.
+type Solution struct {
+	Moves []Move
+	Stats Stats
+	// Cached reports that the solution has been taken from the cache instead of being searched for.
+	Cached bool
+}
+
+// LimitError is returned when a search has been stopped before finding a solution,
+// either by its context or by one of the limits. Holds statistics of the search up to that point.
+type LimitError struct {
+	Err   error
+	Stats Stats
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Search stopped after expanding %d states: %s", e.Stats.NodesExpanded, e.Err)
+}
+
+// Unwrap returns the reason of stopping the search, i.e. ErrNodeLimit or context.DeadlineExceeded.
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Solve finds a solution for the initial board state
+func (board *Board) Solve() ([]Move, error) {
+	solution, err := board.SolveContext(context.Background(), SolveOptions{})
+
+	return solution.Moves, err
+}
+
+// SolveContext finds a solution for the initial board state, unless the context is done
+// or any of the limits is reached first, in which case *LimitError is returned.
+func (board *Board) SolveContext(ctx context.Context, opts SolveOptions) (Solution, error) {
+	return board.SolveFrom(ctx, board.State, opts)
+}
+
+// SolveFrom finds a solution for a given state of the board, like SolveContext does for the initial state.
+// The board is not modified, so it can solve any number of states concurrently.
+func (board *Board) SolveFrom(ctx context.Context, state State, opts SolveOptions) (Solution, error) {
+	search, err := board.NewSearch(state)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	return search.SolveContext(ctx, opts)
+}
+
+// SolveContext runs the search until it finds a solution, the context is done or any of the limits is reached,
+// in which case *LimitError is returned. A search stopped that way can be resumed by calling SolveContext again.
+func (search *Search) SolveContext(ctx context.Context, opts SolveOptions) (Solution, error) {
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = defaultProgressInterval
+	}
+
+	if opts.CheckpointInterval <= 0 {
+		opts.CheckpointInterval = defaultCheckpointInterval
+	}
+
+	if opts.Algorithm == "" {
+		opts.Algorithm = AlgorithmBFS
+	}
+
+	if opts.Checkpoint != "" && opts.Algorithm != AlgorithmBFS {
+		return Solution{}, fmt.Errorf("Checkpoints are not supported by the %s algorithm", opts.Algorithm)
+	}
+
+	if opts.Cache == nil {
+		return search.solve(ctx, opts)
+	}
+
+	start := search.States[0]
+	key := search.Board.CanonicalHash(start)
+
+	if cachedMoves, ok := opts.Cache.Get(key); ok {
+		// Moves not possible from the state or not solving the puzzle can only come from a damaged cache file,
+		// which is then overwritten.
+		if moves, err := search.Board.replayCachedMoves(start, cachedMoves); err == nil {
+			solution := Solution{Moves: moves, Stats: Stats{Depth: len(moves)}, Cached: true}
+
+			if opts.Progress != nil {
+				opts.Progress(solution.Stats)
+			}
+
+			return solution, nil
+		}
+	}
+
+	solution, err := search.solve(ctx, opts)
+
+	if err == nil {
+		// The cache is only an optimisation, so failing to store a solution in a file does not fail the search.
+		opts.Cache.Put(key, getCachedMoves(solution.Moves))
+	}
+
+	return solution, err
+}
+
+// Runs the search with the algorithm given by options.
+func (search *Search) solve(ctx context.Context, opts SolveOptions) (Solution, error) {
+	switch opts.Algorithm {
+	case AlgorithmBFS:
+		return search.solveBFS(ctx, opts)
+	case AlgorithmExternal:
+		return search.Board.solveExternal(ctx, search.States[0], opts)
+	case AlgorithmFrontier:
+		return search.Board.solveFrontier(ctx, search.States[0], opts)
+	}
+
+	return Solution{}, fmt.Errorf("Unknown algorithm %q", opts.Algorithm)
+}
+
+// Solves the board with a breadth-first search keeping all states in memory.
+func (search *Search) solveBFS(ctx context.Context, opts SolveOptions) (Solution, error) {
+	board := search.Board
+	start := time.Now().Add(-search.elapsed)
+	lastProgress, lastCheckpoint := start, time.Now()
+	stateSize := board.getStateSize()
+
+	getStats := func(idx int) Stats {
+		stats := Stats{
+			NodesExpanded: idx,
+			FrontierSize:  len(search.States) - idx,
+			Visited:       len(search.VisitedStatesHashes),
+			Elapsed:       time.Since(start),
+		}
+
+		if idx < len(search.States) {
+			stats.Depth = search.links[idx].depth
+		}
+
+		return stats
+	}
+
+	// Keeps position of the search, so it can be saved in a checkpoint and resumed.
+	pause := func(idx int) {
+		search.expanded, search.elapsed = idx, time.Since(start)
+	}
+
+	finish := func(solution Solution, err error) (Solution, error) {
+		if opts.Progress != nil {
+			opts.Progress(solution.Stats)
+		}
+
+		return solution, err
+	}
+
+	for idx := search.expanded; idx < len(search.States); idx++ {
+
+		if err := board.checkLimits(ctx, opts, idx, search.getMemory(stateSize)); err != nil {
+			stats := getStats(idx)
+			pause(idx)
+
+			if opts.Checkpoint != "" {
+				if err := search.SaveCheckpoint(opts.Checkpoint); err != nil {
+					return finish(Solution{Stats: stats}, err)
+				}
+			}
+
+			return finish(Solution{Stats: stats}, &LimitError{Err: err, Stats: stats})
+		}
+
+		if idx%contextCheckInterval == 0 {
+			if opts.Progress != nil && time.Since(lastProgress) >= opts.ProgressInterval {
+				lastProgress = time.Now()
+				opts.Progress(getStats(idx))
+			}
+
+			if opts.Checkpoint != "" && time.Since(lastCheckpoint) >= opts.CheckpointInterval {
+				lastCheckpoint = time.Now()
+				pause(idx)
+
+				if err := search.SaveCheckpoint(opts.Checkpoint); err != nil {
+					return finish(Solution{Stats: getStats(idx)}, err)
+				}
+			}
+		}
+
+		currentState := search.States[idx]
+
+		search.VisitedStatesHashes[currentState.Hash] = true
+
+		if currentState.isFinal(board.Goal) {
+			pause(idx)
+
+			return finish(Solution{Moves: search.getSolution(idx), Stats: getStats(idx)}, nil)
+		}
+
+		search.findNewStates(idx)
+	}
+
+	pause(len(search.States))
+
+	return finish(Solution{Moves: make([]Move, 0), Stats: getStats(len(search.States))}, ErrNoSolution)
+}
+
+// Returns an error if the search should stop before expanding given number of states.
+// Memory is the estimated number of bytes used by the search.
+func (board *Board) checkLimits(ctx context.Context, opts SolveOptions, expanded int, memory uint64) error {
+	if opts.MaxNodes > 0 && expanded >= opts.MaxNodes {
+		return ErrNodeLimit
+	}
+
+	if expanded%contextCheckInterval == 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+
+	if opts.MaxMemory > 0 && memory > opts.MaxMemory {
+		return ErrMemoryLimit
+	}
+
+	return nil
+}
+
+// Returns the approximate number of bytes used by a state of the board: its pieces and their blocks.
+func (board *Board) getStateSize() uint64 {
+	size := uint64(unsafe.Sizeof(State{})) + uint64(len(board.State.Pieces))*uint64(unsafe.Sizeof(Piece{}))
+
+	for _, piece := range board.State.Pieces {
+		size += uint64(len(piece.Blocks)) * uint64(unsafe.Sizeof(Block{}))
+	}
+
+	return size
+}
+
+// Returns the estimated number of bytes used by the search: its states of a given size, links between them
+// and visited states.
+func (search *Search) getMemory(stateSize uint64) uint64 {
+	return uint64(len(search.States))*stateSize +
+		uint64(len(search.links))*uint64(unsafe.Sizeof(stateLink{})) +
+		uint64(len(search.VisitedStatesHashes))*visitedEntrySize
+}
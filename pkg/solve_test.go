@@ -0,0 +1,238 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSolveContextCancelled(t *testing.T) {
+	board := initBoard()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := board.SolveContext(ctx, SolveOptions{})
+
+	var limitErr *LimitError
+
+	if !errors.As(err, &limitErr) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestSolveContextDeadline(t *testing.T) {
+	board := initBoard()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	time.Sleep(2 * time.Millisecond)
+
+	_, err := board.SolveContext(ctx, SolveOptions{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestSolveContextMaxNodes(t *testing.T) {
+	board := initBoard()
+	maxNodes := 100
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{MaxNodes: maxNodes})
+
+	var limitErr *LimitError
+
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	if limitErr.Stats.NodesExpanded != maxNodes {
+		t.Errorf("Incorrect number of expanded states, got: %d, want: %d", limitErr.Stats.NodesExpanded, maxNodes)
+	}
+
+	if limitErr.Stats.Visited <= maxNodes {
+		t.Errorf("Incorrect number of visited states, got: %d, want more than: %d", limitErr.Stats.Visited, maxNodes)
+	}
+}
+
+func TestSolveContextMaxMemory(t *testing.T) {
+	board := initBoard()
+
+	_, err := board.SolveContext(context.Background(), SolveOptions{MaxMemory: 1})
+
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrMemoryLimit)
+	}
+
+	// The budget is compared with the memory of the search itself, which grows with the visited states,
+	// regardless of the heap of the rest of the process.
+	const maxMemory = 1 << 20
+
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmFrontier} {
+		_, err := board.SolveContext(context.Background(), SolveOptions{Algorithm: algorithm, MaxMemory: maxMemory})
+
+		var limitErr *LimitError
+
+		if !errors.As(err, &limitErr) || !errors.Is(err, ErrMemoryLimit) {
+			t.Fatalf("Incorrect error returned by %s, got: %v, want: %v", algorithm, err, ErrMemoryLimit)
+		}
+
+		if visited := limitErr.Stats.Visited; visited == 0 || visited*visitedEntrySize > maxMemory {
+			t.Errorf("Incorrect number of visited states of %s, got: %d, want at most: %d", algorithm, visited, maxMemory/visitedEntrySize)
+		}
+	}
+}
+
+func TestSolveContextWithinLimits(t *testing.T) {
+	board := initBoard()
+
+	solution, err := board.SolveContext(context.Background(), SolveOptions{MaxNodes: 1000000, MaxMemory: 1 << 40})
+
+	if err != nil || len(solution.Moves) != 90 {
+		t.Errorf("Final state not found, got: %d moves, %v", len(solution.Moves), err)
+	}
+}
+
+func TestSolveContextProgress(t *testing.T) {
+	board := initBoard()
+
+	var reports []Stats
+
+	opts := SolveOptions{
+		Progress: func(stats Stats) {
+			reports = append(reports, stats)
+		},
+		ProgressInterval: time.Nanosecond,
+	}
+
+	solution, err := board.SolveContext(context.Background(), opts)
+
+	if err != nil {
+		t.Fatalf("Final state not found, got: %v", err)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("Progress reported too few times, got: %d, want at least: 2", len(reports))
+	}
+
+	for idx := 1; idx < len(reports); idx++ {
+		if reports[idx].NodesExpanded < reports[idx-1].NodesExpanded || reports[idx].Depth < reports[idx-1].Depth {
+			t.Errorf("Progress went backwards, got: %+v after %+v", reports[idx], reports[idx-1])
+		}
+	}
+
+	stats := solution.Stats
+	last := reports[len(reports)-1]
+
+	if last != stats {
+		t.Errorf("Last progress report differs from final statistics, got: %+v, want: %+v", last, stats)
+	}
+
+	if stats.Depth != len(solution.Moves) || stats.FrontierSize <= 0 || stats.Visited < stats.NodesExpanded+stats.FrontierSize {
+		t.Errorf("Final statistics incorrect, got: %+v", stats)
+	}
+}
+
+func TestSolveFromConcurrently(t *testing.T) {
+	board := initBoard()
+	pieceMoves, _ := ParseMoves("iR jL")
+	states, err := board.ApplyMoves(board.State, pieceMoves)
+
+	if err != nil {
+		t.Fatalf("Cannot apply moves, got: %v", err)
+	}
+
+	starts := append([]State{board.State}, states...)
+	algorithms := []Algorithm{AlgorithmBFS, AlgorithmFrontier, AlgorithmExternal}
+	solutions := make([]Solution, len(starts)*len(algorithms))
+	errs := make([]error, len(solutions))
+	done := make(chan bool)
+
+	for idx := range solutions {
+		go func(idx int) {
+			opts := SolveOptions{Algorithm: algorithms[idx%len(algorithms)], TempDir: t.TempDir()}
+			solutions[idx], errs[idx] = board.SolveFrom(context.Background(), starts[idx/len(algorithms)], opts)
+			done <- true
+		}(idx)
+	}
+
+	for range solutions {
+		<-done
+	}
+
+	for idx, solution := range solutions {
+		start := starts[idx/len(algorithms)]
+
+		if errs[idx] != nil {
+			t.Errorf("Final state not found from state %d, got: %v", idx/len(algorithms), errs[idx])
+			continue
+		}
+
+		if solution.Moves[0].Before.Hash != board.GetZobristHash(start) || !solution.Moves[len(solution.Moves)-1].After.isFinal(board.Goal) {
+			t.Errorf("Solution from state %d does not lead from the state to the final state", idx/len(algorithms))
+		}
+
+		if want := len(solutions[idx-idx%len(algorithms)].Moves); len(solution.Moves) != want {
+			t.Errorf("Incorrect number of moves from state %d, got: %d, want: %d", idx/len(algorithms), len(solution.Moves), want)
+		}
+	}
+
+	if board.State.Hash != board.GetZobristHash(initBoard().State) {
+		t.Error("Board modified by the searches.")
+	}
+}
+
+func TestSolveFromInvalidState(t *testing.T) {
+	board := initBoard()
+	pieces := append([]Piece{}, board.State.Pieces...)
+	pieces[9] = Piece{Label: "j", Width: 1, Height: 1, Blocks: []Block{{X: 2, Y: 3}}}
+
+	states := []State{
+		{Pieces: pieces[:9]},
+		{Pieces: pieces},
+	}
+
+	for _, state := range states {
+		if _, err := board.SolveFrom(context.Background(), state, SolveOptions{}); err == nil {
+			t.Errorf("Error not returned for invalid state, got: %v", state)
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmFrontier, AlgorithmExternal} {
+		b.Run(string(algorithm), func(b *testing.B) {
+			var peakHeap uint64
+
+			opts := SolveOptions{
+				Algorithm: algorithm,
+				Progress: func(Stats) {
+					var memStats runtime.MemStats
+					runtime.ReadMemStats(&memStats)
+
+					if memStats.HeapAlloc > peakHeap {
+						peakHeap = memStats.HeapAlloc
+					}
+				},
+				ProgressInterval: time.Nanosecond,
+			}
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				board := initBoard()
+				runtime.GC()
+
+				if _, err := board.SolveContext(context.Background(), opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(peakHeap), "peak-heap-bytes")
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package klotski
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrSolverClosed is returned by Solver.Solve after the solver has been closed.
+var ErrSolverClosed = errors.New("Solver closed")
+
+// Solver solves boards on a fixed number of workers and is safe to share across goroutines.
+// Identical solves requested while one of them is in progress (the same puzzle and starting state)
+// wait for the same search instead of starting a new one.
+type Solver struct {
+	opts      SolveOptions
+	jobs      chan *solverCall
+	quit      chan struct{}
+	closeOnce sync.Once
+	mutex     sync.Mutex
+	calls     map[string]*solverCall
+	closed    bool
+}
+
+// Solve of a single board state, shared by all requests waiting for it.
+type solverCall struct {
+	key    string
+	board  *Board
+	state  State
+	opts   SolveOptions
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Number of requests waiting for the solution, the search is cancelled when all of them are gone.
+	waiters int
+	// Progress callbacks of the waiting requests by their numbers.
+	progress   map[int]func(Stats)
+	nextWaiter int
+	done       chan struct{}
+	solution   Solution
+	err        error
+}
+
+// NewSolver returns a solver running at most a given number of searches at the same time,
+// each of them with given options.
+func NewSolver(workers int, opts SolveOptions) *Solver {
+	if workers < 1 {
+		workers = 1
+	}
+
+	solver := &Solver{
+		opts:  opts,
+		jobs:  make(chan *solverCall),
+		quit:  make(chan struct{}),
+		calls: make(map[string]*solverCall),
+	}
+
+	for idx := 0; idx < workers; idx++ {
+		go solver.work()
+	}
+
+	return solver
+}
+
+// Solve finds a solution for a given state of a board, waiting for a free worker first.
+// Returns when the solution is found or the context is done, whichever comes first.
+// The solution may be shared with other requests (and found on the board of the first of them),
+// so its moves must not be modified.
+func (solver *Solver) Solve(ctx context.Context, board *Board, state State) (Solution, error) {
+	return solver.SolveWithOptions(ctx, board, state, solver.opts)
+}
+
+// SolveWithOptions is Solve with options other than the ones given to the solver, i.e. another algorithm
+// or limits. Only requests with the same algorithm, limits, checkpoint and cache share searches.
+// Progress of a shared search is reported to all requests waiting for it, at the interval of the first of them.
+func (solver *Solver) SolveWithOptions(ctx context.Context, board *Board, state State, opts SolveOptions) (Solution, error) {
+	call, waiter, err := solver.join(board, state, opts)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	select {
+	case <-call.done:
+		return call.solution, call.err
+	case <-ctx.Done():
+		solver.leave(call, waiter)
+
+		return Solution{}, &LimitError{Err: ctx.Err()}
+	}
+}
+
+// Close stops the workers. Searches in progress are cancelled and waiting requests return ErrSolverClosed.
+func (solver *Solver) Close() {
+	solver.closeOnce.Do(func() {
+		solver.mutex.Lock()
+		solver.closed = true
+		solver.mutex.Unlock()
+
+		close(solver.quit)
+	})
+}
+
+// Returns a solve in progress for a given board state and options or starts a new one,
+// and the number of the request waiting for it.
+func (solver *Solver) join(board *Board, state State, opts SolveOptions) (*solverCall, int, error) {
+	if err := board.validateState(state); err != nil {
+		return nil, 0, err
+	}
+
+	key := getSolverKey(board, state, opts)
+
+	solver.mutex.Lock()
+	defer solver.mutex.Unlock()
+
+	if solver.closed {
+		return nil, 0, ErrSolverClosed
+	}
+
+	call, ok := solver.calls[key]
+
+	if !ok {
+		call = &solverCall{key: key, board: board, state: state, opts: opts, progress: make(map[int]func(Stats)), done: make(chan struct{})}
+		call.ctx, call.cancel = context.WithCancel(context.Background())
+		call.opts.Progress = func(stats Stats) { solver.report(call, stats) }
+		solver.calls[key] = call
+
+		go solver.submit(call)
+	}
+
+	waiter := call.nextWaiter
+	call.nextWaiter++
+	call.waiters++
+
+	if opts.Progress != nil {
+		call.progress[waiter] = opts.Progress
+	}
+
+	return call, waiter, nil
+}
+
+// Stops waiting for a solve, cancelling it if nobody else waits for it.
+func (solver *Solver) leave(call *solverCall, waiter int) {
+	solver.mutex.Lock()
+	defer solver.mutex.Unlock()
+
+	call.waiters--
+	delete(call.progress, waiter)
+
+	if call.waiters == 0 {
+		call.cancel()
+
+		if solver.calls[call.key] == call {
+			delete(solver.calls, call.key)
+		}
+	}
+}
+
+// Reports progress of a solve to all requests waiting for it.
+func (solver *Solver) report(call *solverCall, stats Stats) {
+	solver.mutex.Lock()
+	progress := make([]func(Stats), 0, len(call.progress))
+
+	for _, fn := range call.progress {
+		progress = append(progress, fn)
+	}
+
+	solver.mutex.Unlock()
+
+	for _, fn := range progress {
+		fn(stats)
+	}
+}
+
+// Hands a solve to the first free worker.
+func (solver *Solver) submit(call *solverCall) {
+	select {
+	case solver.jobs <- call:
+	case <-call.ctx.Done():
+		solver.finish(call, Solution{}, &LimitError{Err: call.ctx.Err()})
+	case <-solver.quit:
+		solver.finish(call, Solution{}, ErrSolverClosed)
+	}
+}
+
+// Runs solves until the solver is closed.
+func (solver *Solver) work() {
+	for {
+		select {
+		case call := <-solver.jobs:
+			solver.run(call)
+		case <-solver.quit:
+			return
+		}
+	}
+}
+
+// Runs a single solve, cancelled when the solver is closed.
+func (solver *Solver) run(call *solverCall) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-solver.quit:
+			call.cancel()
+		case <-stop:
+		}
+	}()
+
+	solution, err := call.board.SolveFrom(call.ctx, call.state, call.opts)
+
+	select {
+	case <-solver.quit:
+		if err != nil {
+			err = ErrSolverClosed
+		}
+	default:
+	}
+
+	solver.finish(call, solution, err)
+}
+
+// Stores the result of a solve and wakes up requests waiting for it.
+func (solver *Solver) finish(call *solverCall, solution Solution, err error) {
+	solver.mutex.Lock()
+
+	if solver.calls[call.key] == call {
+		delete(solver.calls, call.key)
+	}
+
+	solver.mutex.Unlock()
+
+	call.solution, call.err = solution, err
+	call.cancel()
+	close(call.done)
+}
+
+// Returns a key identifying solves of a board state: the algorithm, limits, checkpoint and cache of the search,
+// the size and goal of the board and positions of all pieces.
+func getSolverKey(board *Board, state State, opts SolveOptions) string {
+	var buffer bytes.Buffer
+
+	algorithm := opts.Algorithm
+
+	if algorithm == "" {
+		algorithm = AlgorithmBFS
+	}
+
+	buffer.WriteString(fmt.Sprintf("%s %d %d %q %p\n", algorithm, opts.MaxNodes, opts.MaxMemory, opts.Checkpoint, opts.Cache))
+	buffer.WriteString(fmt.Sprintf("%dx%d %s %d %d\n", board.Width, board.Height, board.Goal.Label, board.Goal.X, board.Goal.Y))
+
+	for _, row := range state.getMatrix(board.Width, board.Height) {
+		buffer.WriteString(strings.Join(row, " ") + "\n")
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,175 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSolverDeduplicates(t *testing.T) {
+	var mutex sync.Mutex
+	var solver *Solver
+
+	reports := 0
+	deadline := time.After(10 * time.Second)
+
+	opts := SolveOptions{
+		ProgressInterval: time.Hour,
+		// Progress is only reported when a search finishes, which waits until all requests have joined
+		// the solver, so none of them can start another search after the first one.
+		Progress: func(Stats) {
+			for getSolverWaiters(solver) < 10 {
+				select {
+				case <-deadline:
+					t.Error("Requests not joined the solver before the deadline")
+
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+
+			mutex.Lock()
+			reports++
+			mutex.Unlock()
+		},
+	}
+
+	solver = NewSolver(2, opts)
+	defer solver.Close()
+
+	board := initBoard()
+	results := make(chan Solution)
+	errs := make(chan error, 10)
+
+	for idx := 0; idx < 10; idx++ {
+		go func() {
+			solution, err := solver.Solve(context.Background(), &board, board.State)
+			if err != nil {
+				errs <- err
+			}
+			results <- solution
+		}()
+	}
+
+	// Requests sharing a search get the same moves.
+	searches := make(map[*Move]bool)
+
+	for idx := 0; idx < 10; idx++ {
+		solution := <-results
+
+		if len(solution.Moves) != 90 {
+			t.Errorf("Incorrect number of moves, got: %d, want: %d", len(solution.Moves), 90)
+
+			continue
+		}
+
+		searches[&solution.Moves[0]] = true
+	}
+
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Final state not found, got: %v", err)
+	}
+
+	if len(searches) != 1 {
+		t.Errorf("Incorrect number of searches, got: %d, want: %d", len(searches), 1)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// The final progress of the search is reported to each of the requests.
+	if reports != 10 {
+		t.Errorf("Incorrect number of progress reports, got: %d, want: %d", reports, 10)
+	}
+}
+
+func TestSolverCancelled(t *testing.T) {
+	solver := NewSolver(1, SolveOptions{})
+	defer solver.Close()
+
+	board := initBoard()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := solver.Solve(ctx, &board, board.State)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+
+	solution, err := solver.Solve(context.Background(), &board, board.State)
+
+	if err != nil || len(solution.Moves) != 90 {
+		t.Errorf("Final state not found after a cancelled request, got: %d moves, %v", len(solution.Moves), err)
+	}
+}
+
+func TestSolverClosed(t *testing.T) {
+	solver := NewSolver(1, SolveOptions{})
+	board := initBoard()
+
+	solver.Close()
+	solver.Close()
+
+	if _, err := solver.Solve(context.Background(), &board, board.State); !errors.Is(err, ErrSolverClosed) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrSolverClosed)
+	}
+}
+
+func TestSolverSolveWithOptions(t *testing.T) {
+	solver := NewSolver(2, SolveOptions{})
+	defer solver.Close()
+
+	board := initBoard()
+
+	_, err := solver.SolveWithOptions(context.Background(), &board, board.State, SolveOptions{MaxNodes: 10})
+
+	if !errors.Is(err, ErrNodeLimit) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrNodeLimit)
+	}
+
+	solution, err := solver.SolveWithOptions(context.Background(), &board, board.State, SolveOptions{Algorithm: AlgorithmFrontier})
+
+	if err != nil || len(solution.Moves) != 90 {
+		t.Errorf("Final state not found with the frontier algorithm, got: %d moves, %v", len(solution.Moves), err)
+	}
+
+	if key := getSolverKey(&board, board.State, SolveOptions{}); key != getSolverKey(&board, board.State, SolveOptions{Algorithm: AlgorithmBFS}) {
+		t.Errorf("Different keys of solves with the default algorithm and %s", AlgorithmBFS)
+	}
+
+	if key := getSolverKey(&board, board.State, SolveOptions{}); key == getSolverKey(&board, board.State, SolveOptions{MaxMemory: 1 << 30}) {
+		t.Error("Same keys of solves with different memory limits")
+	}
+
+	if key := getSolverKey(&board, board.State, SolveOptions{}); key == getSolverKey(&board, board.State, SolveOptions{MaxNodes: 10}) {
+		t.Error("Same keys of solves with different node limits")
+	}
+
+	if key := getSolverKey(&board, board.State, SolveOptions{}); key == getSolverKey(&board, board.State, SolveOptions{Checkpoint: "search.gob"}) {
+		t.Error("Same keys of solves with different checkpoints")
+	}
+
+	if key := getSolverKey(&board, board.State, SolveOptions{}); key == getSolverKey(&board, board.State, SolveOptions{Cache: &SolutionCache{}}) {
+		t.Error("Same keys of solves with different caches")
+	}
+}
+
+// Returns the number of requests waiting for solves of a solver.
+func getSolverWaiters(solver *Solver) int {
+	solver.mutex.Lock()
+	defer solver.mutex.Unlock()
+
+	waiters := 0
+
+	for _, call := range solver.calls {
+		waiters += call.waiters
+	}
+
+	return waiters
+}
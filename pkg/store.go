@@ -0,0 +1,356 @@
+package klotski
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPuzzleNotFound is returned by Store when there is no puzzle with a given ID.
+	ErrPuzzleNotFound = errors.New("Puzzle not found")
+
+	// ErrPuzzleExists is returned by Store.Insert when a puzzle with the same ID is stored already.
+	ErrPuzzleExists = errors.New("Puzzle exists already")
+
+	// ErrInvalidRating is returned by Store.Rate for ratings out of the MinRating to MaxRating range.
+	ErrInvalidRating = errors.New("Invalid rating")
+)
+
+// Returned by functions updating stored puzzles when there is nothing to write.
+var errRecordUnchanged = errors.New("Puzzle unchanged")
+
+// Lowest and highest rating of a puzzle.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Store keeps puzzles, their solutions, statistics of searches and ratings in a directory, one JSON file per puzzle,
+// so solved puzzles can be browsed later instead of being solved again. It is safe to share across goroutines,
+// but not across processes.
+type Store struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// StoredPuzzle is a puzzle kept by Store.
+type StoredPuzzle struct {
+	// ID of the puzzle, the slug of its name, i.e. "heng-dao-li-ma".
+	ID     string
+	Puzzle Puzzle `json:"-"`
+	Solved bool
+	// Solution in compact notation and statistics of its search.
+	Solution string
+	Stats    Stats
+	// Number and sum of ratings given to the puzzle.
+	Ratings     int
+	RatingTotal int
+	Created     time.Time
+	Updated     time.Time
+
+	// Puzzle in the text grid format, as it is stored in a file.
+	Text string
+}
+
+// OpenStore returns a store keeping puzzles in a given directory, created if needed.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("Cannot open store: %s", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// GetStoredPuzzleID returns ID of a puzzle in a store: the slug of its name or, for puzzles without a name,
+// a prefix of the canonical hash of its initial state.
+func GetStoredPuzzleID(puzzle Puzzle) string {
+	if id := slug(puzzle.Name); id != "" {
+		return id
+	}
+
+	board := puzzle.Board()
+
+	return board.CanonicalHash(board.State)[:12]
+}
+
+// List returns all stored puzzles sorted by name.
+func (store *Store) List() ([]StoredPuzzle, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]StoredPuzzle, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		record, err := store.read(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Puzzle.Name < records[j].Puzzle.Name
+	})
+
+	return records, nil
+}
+
+// Get returns a stored puzzle by its ID.
+func (store *Store) Get(id string) (StoredPuzzle, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.read(id)
+}
+
+// GetPuzzle returns the stored puzzle with the ID of a given puzzle. Puzzles are stored by their names, so it returns
+// ErrPuzzleNotFound if the stored puzzle has another layout, i.e. one of an edited file.
+func (store *Store) GetPuzzle(puzzle Puzzle) (StoredPuzzle, error) {
+	id := GetStoredPuzzleID(puzzle)
+
+	record, err := store.Get(id)
+	if err != nil {
+		return record, err
+	}
+
+	if !isSameLayout(record.Puzzle, puzzle) {
+		return StoredPuzzle{}, fmt.Errorf("%w: %s with the same layout", ErrPuzzleNotFound, id)
+	}
+
+	return record, nil
+}
+
+// Insert stores a new puzzle. Returns ErrPuzzleExists if a puzzle with the same ID is stored already.
+func (store *Store) Insert(puzzle Puzzle) (StoredPuzzle, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	id := GetStoredPuzzleID(puzzle)
+
+	if _, err := os.Stat(store.getPath(id)); err == nil {
+		return StoredPuzzle{}, fmt.Errorf("%w: %s", ErrPuzzleExists, id)
+	}
+
+	now := time.Now().UTC()
+	record := StoredPuzzle{ID: id, Puzzle: puzzle, Created: now, Updated: now}
+
+	return record, store.write(record)
+}
+
+// Delete removes a stored puzzle.
+func (store *Store) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if !isValidStoredPuzzleID(id) {
+		return fmt.Errorf("%w: %s", ErrPuzzleNotFound, id)
+	}
+
+	if err := os.Remove(store.getPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrPuzzleNotFound, id)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// SaveSolution stores a solution of a puzzle and statistics of its search.
+func (store *Store) SaveSolution(id string, solution Solution) (StoredPuzzle, error) {
+	return store.update(id, func(record *StoredPuzzle) error {
+		record.setSolution(solution)
+
+		return nil
+	})
+}
+
+// SavePuzzleSolution stores a solution of a puzzle, inserting the puzzle first if it is not stored yet.
+// A puzzle stored with the same solution already is not written again. Returns ErrPuzzleExists if a puzzle with the same ID but another layout is stored already, which is kept.
+func (store *Store) SavePuzzleSolution(puzzle Puzzle, solution Solution) (StoredPuzzle, error) {
+	if _, err := store.Insert(puzzle); err != nil && !errors.Is(err, ErrPuzzleExists) {
+		return StoredPuzzle{}, err
+	}
+
+	id := GetStoredPuzzleID(puzzle)
+
+	return store.update(id, func(record *StoredPuzzle) error {
+		if !isSameLayout(record.Puzzle, puzzle) {
+			return fmt.Errorf("%w: %s with another layout", ErrPuzzleExists, id)
+		}
+
+		// Statistics of the search which found the stored solution are kept.
+		if record.Solved && record.Solution == FormatMoves(Notation(solution.Moves)) {
+			return errRecordUnchanged
+		}
+
+		record.setSolution(solution)
+
+		return nil
+	})
+}
+
+// Rate adds a rating of a puzzle, between MinRating and MaxRating.
+func (store *Store) Rate(id string, rating int) (StoredPuzzle, error) {
+	if rating < MinRating || rating > MaxRating {
+		return StoredPuzzle{}, fmt.Errorf("%w %d, want: %d to %d", ErrInvalidRating, rating, MinRating, MaxRating)
+	}
+
+	return store.update(id, func(record *StoredPuzzle) error {
+		record.Ratings++
+		record.RatingTotal += rating
+
+		return nil
+	})
+}
+
+// Rating returns the average rating of a puzzle or 0 if it has not been rated yet.
+func (record *StoredPuzzle) Rating() float64 {
+	if record.Ratings == 0 {
+		return 0
+	}
+
+	return float64(record.RatingTotal) / float64(record.Ratings)
+}
+
+// SolutionStates returns states after each move of the stored solution, replayed from the initial state of the puzzle.
+func (record *StoredPuzzle) SolutionStates() ([]State, error) {
+	pieceMoves, err := ParseMoves(record.Solution)
+	if err != nil {
+		return nil, err
+	}
+
+	board := record.Puzzle.Board()
+
+	return board.ApplyMoves(board.State, pieceMoves)
+}
+
+// Sets the solution of a stored puzzle and statistics of its search.
+func (record *StoredPuzzle) setSolution(solution Solution) {
+	record.Solved = true
+	record.Solution = FormatMoves(Notation(solution.Moves))
+	record.Stats = solution.Stats
+}
+
+// Reads a stored puzzle, updates it with a function and writes it back, unless the function returns
+// errRecordUnchanged.
+func (store *Store) update(id string, fn func(record *StoredPuzzle) error) (StoredPuzzle, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	record, err := store.read(id)
+	if err != nil {
+		return record, err
+	}
+
+	if err := fn(&record); err != nil {
+		if errors.Is(err, errRecordUnchanged) {
+			return record, nil
+		}
+
+		return record, err
+	}
+
+	record.Updated = time.Now().UTC()
+
+	return record, store.write(record)
+}
+
+// Reads a stored puzzle from its file.
+func (store *Store) read(id string) (StoredPuzzle, error) {
+	var record StoredPuzzle
+
+	if !isValidStoredPuzzleID(id) {
+		return record, fmt.Errorf("%w: %s", ErrPuzzleNotFound, id)
+	}
+
+	content, err := os.ReadFile(store.getPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return record, fmt.Errorf("%w: %s", ErrPuzzleNotFound, id)
+		}
+
+		return record, err
+	}
+
+	if err := json.Unmarshal(content, &record); err != nil {
+		return record, fmt.Errorf("Invalid stored puzzle %s: %s", id, err)
+	}
+
+	if record.Puzzle, err = ParsePuzzle(record.Text); err != nil {
+		return record, fmt.Errorf("Invalid stored puzzle %s: %s", id, err)
+	}
+
+	return record, nil
+}
+
+// Writes a stored puzzle to its file, replacing it atomically.
+func (store *Store) write(record StoredPuzzle) error {
+	record.Text = record.Puzzle.String()
+
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(store.dir, record.ID+".tmp")
+	if err != nil {
+		return fmt.Errorf("Cannot store puzzle: %s", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("Cannot store puzzle: %s", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("Cannot store puzzle: %s", err)
+	}
+
+	if err := os.Rename(file.Name(), store.getPath(record.ID)); err != nil {
+		return fmt.Errorf("Cannot store puzzle: %s", err)
+	}
+
+	return nil
+}
+
+// Returns path of a file storing a puzzle with a given ID.
+func (store *Store) getPath(id string) string {
+	return filepath.Join(store.dir, id+".json")
+}
+
+// Checks that an ID cannot point outside of the store directory.
+func isValidStoredPuzzleID(id string) bool {
+	return id != "" && id == slug(id)
+}
+
+// Checks that two puzzles have the same layout: the size of the board, the goal, and labels and positions of pieces.
+func isSameLayout(a, b Puzzle) bool {
+	if a.Width != b.Width || a.Height != b.Height || a.Goal != b.Goal {
+		return false
+	}
+
+	stateA, stateB := State{Pieces: a.Pieces}, State{Pieces: b.Pieces}
+
+	return strings.Join(stateA.getGrid(a.Width, a.Height), "\n") == strings.Join(stateB.getGrid(b.Width, b.Height), "\n")
+}
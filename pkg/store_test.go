@@ -0,0 +1,143 @@
+package klotski
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	store, err := OpenStore(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("Cannot open store, got: %v", err)
+	}
+
+	puzzle, _ := FindPuzzle("Heng Dao Li Ma")
+	record, err := store.Insert(puzzle)
+
+	if err != nil || record.ID != "heng-dao-li-ma" || record.Solved {
+		t.Fatalf("Puzzle not inserted, got: %+v, %v", record, err)
+	}
+
+	if _, err := store.Insert(puzzle); !errors.Is(err, ErrPuzzleExists) {
+		t.Errorf("Incorrect error returned, got: %v, want: %v", err, ErrPuzzleExists)
+	}
+
+	board := puzzle.Board()
+	solution, _ := board.SolveContext(context.Background(), SolveOptions{})
+
+	if _, err := store.SaveSolution(record.ID, solution); err != nil {
+		t.Fatalf("Solution not saved, got: %v", err)
+	}
+
+	store.Rate(record.ID, 5)
+	store.Rate(record.ID, 2)
+
+	if _, err := store.Rate(record.ID, 6); err == nil {
+		t.Error("Error not returned for invalid rating.")
+	}
+
+	record, err = store.Get(record.ID)
+
+	if err != nil || !record.Solved || record.Stats.Depth != puzzle.Moves || record.Rating() != 3.5 || record.Puzzle.String() != puzzle.String() {
+		t.Fatalf("Incorrect stored puzzle, got: %+v, %v", record, err)
+	}
+
+	states, err := record.SolutionStates()
+
+	if err != nil || len(states) != puzzle.Moves || !states[len(states)-1].isFinal(puzzle.Goal) {
+		t.Errorf("Stored solution does not reach the final state, got: %v", err)
+	}
+
+	if err := store.Delete(record.ID); err != nil {
+		t.Errorf("Puzzle not deleted, got: %v", err)
+	}
+
+	for _, id := range []string{record.ID, "../heng-dao-li-ma", ""} {
+		if _, err := store.Get(id); !errors.Is(err, ErrPuzzleNotFound) {
+			t.Errorf("Incorrect error returned for %q, got: %v, want: %v", id, err, ErrPuzzleNotFound)
+		}
+
+		if err := store.Delete(id); !errors.Is(err, ErrPuzzleNotFound) {
+			t.Errorf("Incorrect error returned when deleting %q, got: %v, want: %v", id, err, ErrPuzzleNotFound)
+		}
+	}
+}
+
+func TestStorePuzzleLayout(t *testing.T) {
+	store, _ := OpenStore(t.TempDir())
+
+	puzzle, _ := ParsePuzzle("name: Edited\ngoal: a 1 0\n\na . .\n")
+	edited, _ := ParsePuzzle("name: Edited\ngoal: a 2 0\n\na . .\n")
+	board := puzzle.Board()
+	solution, _ := board.SolveContext(context.Background(), SolveOptions{})
+
+	if _, err := store.SavePuzzleSolution(puzzle, solution); err != nil {
+		t.Fatalf("Solution not saved, got: %v", err)
+	}
+
+	// A puzzle of the same name but another layout, i.e. of an edited file, does not replace the stored one.
+	if _, err := store.SavePuzzleSolution(edited, Solution{Moves: make([]Move, 0)}); !errors.Is(err, ErrPuzzleExists) {
+		t.Errorf("Incorrect error returned for another layout, got: %v, want: %v", err, ErrPuzzleExists)
+	}
+
+	if _, err := store.GetPuzzle(edited); !errors.Is(err, ErrPuzzleNotFound) {
+		t.Errorf("Incorrect error returned for another layout, got: %v, want: %v", err, ErrPuzzleNotFound)
+	}
+
+	record, err := store.GetPuzzle(puzzle)
+
+	if err != nil || record.Solution != FormatMoves(Notation(solution.Moves)) || record.Puzzle.String() != puzzle.String() {
+		t.Errorf("Incorrect stored puzzle, got: %+v, %v", record, err)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	store, _ := OpenStore(t.TempDir())
+	puzzles, _ := Catalog()
+
+	for idx := len(puzzles) - 1; idx >= 0; idx-- {
+		store.Insert(puzzles[idx])
+	}
+
+	unnamed, _ := ParsePuzzle("goal: a 1 0\n\na .\n")
+	record, err := store.SavePuzzleSolution(unnamed, Solution{Moves: make([]Move, 0)})
+
+	if err != nil || len(record.ID) != 12 || !record.Solved {
+		t.Errorf("Unnamed puzzle not stored, got: %+v, %v", record, err)
+	}
+
+	records, err := store.List()
+
+	if err != nil || len(records) != len(puzzles)+1 {
+		t.Fatalf("Incorrect number of stored puzzles, got: %d, %v, want: %d", len(records), err, len(puzzles)+1)
+	}
+
+	for idx, puzzle := range puzzles {
+		if records[idx+1].Puzzle.Name != puzzle.Name {
+			t.Errorf("Incorrect order of stored puzzles, got: %s, want: %s", records[idx+1].Puzzle.Name, puzzle.Name)
+		}
+	}
+}
+
+func TestStoreSameSolution(t *testing.T) {
+	store, _ := OpenStore(t.TempDir())
+
+	puzzle, _ := ParsePuzzle("name: Same\ngoal: a 2 0\n\na . .\n")
+	board := puzzle.Board()
+	solution, _ := board.SolveContext(context.Background(), SolveOptions{})
+	saved, err := store.SavePuzzleSolution(puzzle, solution)
+
+	if err != nil || saved.Stats.NodesExpanded == 0 {
+		t.Fatalf("Solution not saved, got: %+v, %v", saved, err)
+	}
+
+	// The same moves taken from a cache, with only the depth of their statistics known.
+	cached := Solution{Moves: solution.Moves, Stats: Stats{Depth: len(solution.Moves)}, Cached: true}
+	record, err := store.SavePuzzleSolution(puzzle, cached)
+
+	if err != nil || record.Stats != saved.Stats || !record.Updated.Equal(saved.Updated) {
+		t.Errorf("Stored puzzle with the same solution changed, got: %+v, %v, want: %+v", record, err, saved)
+	}
+}
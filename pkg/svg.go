@@ -0,0 +1,211 @@
+package klotski
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+)
+
+// Default size of a space of boards in images, in pixels
+const DefaultCellSize = 40
+
+// Default number of frames in a row of solutions rendered by SVGRenderer.RenderSolution and RasterRenderer.DrawSolution
+const DefaultColumns = 6
+
+// Colors of boards in images
+var (
+	backgroundColor = color.RGBA{R: 0xf4, G: 0xf1, B: 0xea, A: 0xff}
+	wallColor       = color.RGBA{R: 0x58, G: 0x58, B: 0x58, A: 0xff}
+	outlineColor    = color.RGBA{R: 0x38, G: 0x38, B: 0x38, A: 0xff}
+	textColor       = color.RGBA{R: 0x38, G: 0x38, B: 0x38, A: 0xff}
+)
+
+// SVGRenderer renders states of boards as SVG images: pieces as rounded rectangles with their labels,
+// the highlighted piece in white and the exit in green. It implements Renderer.
+type SVGRenderer struct {
+	// Size of a space in pixels, DefaultCellSize if it is 0.
+	CellSize int
+}
+
+// Render returns an SVG image of a state of a board with a piece of a given label highlighted.
+func (renderer SVGRenderer) Render(board *Board, state State, highlight string) string {
+	var buffer bytes.Buffer
+
+	width, height := renderer.getBoardSize(board)
+
+	writeSVGStart(&buffer, width, height)
+	renderer.writeBoard(&buffer, board, state, highlight)
+	buffer.WriteString("</svg>\n")
+
+	return buffer.String()
+}
+
+// RenderSolution returns an SVG image of a solution given as moves in compact notation and states after them:
+// the initial state and the state after each move, in rows of a given number of frames (DefaultColumns if it is
+// not positive). Each frame has a caption with the move and the moved piece highlighted.
+func (renderer SVGRenderer) RenderSolution(board *Board, initialState State, notation []PieceMove, states []State, columns int) string {
+	var buffer bytes.Buffer
+
+	if columns <= 0 {
+		columns = DefaultColumns
+	}
+
+	if columns > len(states)+1 {
+		columns = len(states) + 1
+	}
+
+	boardWidth, boardHeight := renderer.getBoardSize(board)
+	caption, spacing := renderer.getCellSize()/2, renderer.getCellSize()/2
+	frameWidth, frameHeight := boardWidth+spacing, caption+boardHeight+spacing
+	rows := (len(states) + columns) / columns
+
+	writeSVGStart(&buffer, columns*frameWidth-spacing, rows*frameHeight-spacing)
+
+	for step := 0; step <= len(states); step++ {
+		state, highlight, title := initialState, "", "Initial state"
+
+		if step > 0 {
+			state, highlight, title = states[step-1], notation[step-1].Label, fmt.Sprintf("%d) %s", step, notation[step-1])
+		}
+
+		x, y := step%columns*frameWidth, step/columns*frameHeight
+
+		buffer.WriteString(fmt.Sprintf("<g transform=\"translate(%d %d)\">\n", x, y))
+		buffer.WriteString(fmt.Sprintf("<text x=\"0\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" fill=\"%s\">%s</text>\n",
+			caption*2/3, caption*2/3, getHexColor(textColor), html.EscapeString(title)))
+		buffer.WriteString(fmt.Sprintf("<g transform=\"translate(0 %d)\">\n", caption))
+		renderer.writeBoard(&buffer, board, state, highlight)
+		buffer.WriteString("</g>\n</g>\n")
+	}
+
+	buffer.WriteString("</svg>\n")
+
+	return buffer.String()
+}
+
+// Writes elements of a state of a board, with its top left corner at the origin.
+func (renderer SVGRenderer) writeBoard(buffer *bytes.Buffer, board *Board, state State, highlight string) {
+	size := renderer.getCellSize()
+	wall := getWallSize(size)
+	width, height := renderer.getBoardSize(board)
+
+	buffer.WriteString(fmt.Sprintf("<rect width=\"%d\" height=\"%d\" rx=\"%d\" fill=\"%s\"/>\n", width, height, wall/2, getHexColor(wallColor)))
+	buffer.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+		wall, wall, board.Width*size, board.Height*size, getHexColor(backgroundColor)))
+
+	for _, exit := range board.getExitRects(size) {
+		buffer.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+			exit.Min.X, exit.Min.Y, exit.Dx(), exit.Dy(), getHexColor(getPaletteColor(exitColor))))
+	}
+
+	for pieceIdx, piece := range state.Pieces {
+		fill, stroke := getHexColor(getPaletteColor(getPieceColor(board, state, pieceIdx))), getHexColor(outlineColor)
+		labelColor := "#ffffff"
+
+		if piece.Label == highlight {
+			fill, labelColor = getHexColor(getPaletteColor(highlightColor)), getHexColor(outlineColor)
+		}
+
+		bounds := getPieceBounds(piece, size, wall)
+
+		buffer.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"%d\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%d\"/>\n",
+			bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy(), size/6, fill, stroke, getStrokeWidth(size)))
+
+		buffer.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" font-weight=\"bold\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%s</text>\n",
+			(bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2, size/2, labelColor, html.EscapeString(piece.Label)))
+	}
+}
+
+// Returns the size of a space in pixels.
+func (renderer SVGRenderer) getCellSize() int {
+	if renderer.CellSize <= 0 {
+		return DefaultCellSize
+	}
+
+	return renderer.CellSize
+}
+
+// Returns the size of a board with walls around it in pixels.
+func (renderer SVGRenderer) getBoardSize(board *Board) (int, int) {
+	size := renderer.getCellSize()
+	wall := getWallSize(size)
+
+	return board.Width*size + 2*wall, board.Height*size + 2*wall
+}
+
+// Writes the start of an SVG image of a given size.
+func writeSVGStart(buffer *bytes.Buffer, width, height int) {
+	buffer.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height))
+}
+
+// Returns the thickness of walls around boards for a given size of a space.
+func getWallSize(size int) int {
+	return (size + 3) / 4
+}
+
+// Returns the width of outlines of pieces for a given size of a space.
+func getStrokeWidth(size int) int {
+	return (size + 19) / 20
+}
+
+// Returns the gap between pieces and their spaces for a given size of a space.
+func getPieceGap(size int) int {
+	return (size + 19) / 20
+}
+
+// Returns a color in the hexadecimal notation of CSS.
+func getHexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Returns rectangles of the exit in walls of a board, for a given size of a space and walls as thick as getWallSize.
+func (board *Board) getExitRects(size int) []image.Rectangle {
+	var rects []image.Rectangle
+
+	wall := getWallSize(size)
+
+	for x := 0; x < board.Width; x++ {
+		if board.IsExit(x, -1) {
+			rects = append(rects, image.Rect(wall+x*size, 0, wall+(x+1)*size, wall))
+		}
+
+		if board.IsExit(x, board.Height) {
+			rects = append(rects, image.Rect(wall+x*size, wall+board.Height*size, wall+(x+1)*size, 2*wall+board.Height*size))
+		}
+	}
+
+	for y := 0; y < board.Height; y++ {
+		if board.IsExit(-1, y) {
+			rects = append(rects, image.Rect(0, wall+y*size, wall, wall+(y+1)*size))
+		}
+
+		if board.IsExit(board.Width, y) {
+			rects = append(rects, image.Rect(wall+board.Width*size, wall+y*size, 2*wall+board.Width*size, wall+(y+1)*size))
+		}
+	}
+
+	return rects
+}
+
+// Returns the bounding rectangle of a piece in pixels, shrunk by the gap between pieces, for a given size
+// of a space and an offset of the first space.
+func getPieceBounds(piece Piece, size, offset int) image.Rectangle {
+	min := piece.Blocks[0]
+
+	for _, block := range piece.Blocks {
+		if block.X < min.X {
+			min.X = block.X
+		}
+
+		if block.Y < min.Y {
+			min.Y = block.Y
+		}
+	}
+
+	gap := getPieceGap(size)
+	x, y := offset+min.X*size, offset+min.Y*size
+
+	return image.Rect(x+gap, y+gap, x+piece.Width*size-gap, y+piece.Height*size-gap)
+}
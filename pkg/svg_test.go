@@ -0,0 +1,62 @@
+package klotski
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSVGRenderer(t *testing.T) {
+	puzzle, _ := ParsePuzzle("goal: a 0 1\n\na a .\na a b\n. . b\n")
+	board := puzzle.Board()
+	renderer := SVGRenderer{CellSize: 20}
+
+	image := renderer.Render(&board, board.State, "b")
+
+	elements := countSVGElements(t, image)
+
+	// Walls, the background, 2 spaces of the exit below the goal position and 2 on the left of it, and both pieces.
+	if elements["svg"] != 1 || elements["rect"] != 8 || elements["text"] != 2 {
+		t.Errorf("Incorrect elements of the image, got: %v", elements)
+	}
+
+	if !strings.Contains(image, "width=\"70\" height=\"70\"") || !strings.Contains(image, ">b</text>") {
+		t.Errorf("Incorrect image, got:\n%s", image)
+	}
+
+	moves := board.Moves(board.State)
+	states := make([]State, len(moves))
+
+	for idx, move := range moves {
+		states[idx] = move.After
+	}
+
+	strip := renderer.RenderSolution(&board, board.State, Notation(moves), states, 2)
+	elements = countSVGElements(t, strip)
+
+	if elements["g"] != 2*(len(moves)+1) || elements["text"] != 3*(len(moves)+1) {
+		t.Errorf("Incorrect elements of the solution, got: %v", elements)
+	}
+}
+
+// Returns numbers of elements of an SVG image by their names, failing if it is not a valid XML document.
+func countSVGElements(t *testing.T, image string) map[string]int {
+	elements := make(map[string]int)
+	decoder := xml.NewDecoder(strings.NewReader(image))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return elements
+		}
+
+		if err != nil {
+			t.Fatalf("Invalid SVG image, got: %v", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			elements[start.Name.Local]++
+		}
+	}
+}